@@ -5,6 +5,7 @@ import (
 
 	"github.com/joesonw/proto-tools/pkg/genutil"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
 
 	"github.com/joesonw/proto-web/cmd/protoc-gen-pw-http-server/plugin"
 	protoutil2 "github.com/joesonw/proto-web/pkg/protoutil"
@@ -29,6 +30,7 @@ func main() {
 		ParamFunc:         flags.Set,
 		ImportRewriteFunc: importRewriteFunc,
 	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
 		plg := &plugin.Plugin{}
 		for _, file := range gen.Files {
 			if !file.Generate {
@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joesonw/proto-tools/pkg/genutil"
+	"google.golang.org/protobuf/compiler/protogen"
+
+	protoutil2 "github.com/joesonw/proto-web/pkg/protoutil"
+)
+
+// paramsSource returns the expression of the value of v from params.
+func paramsSource(v protoutil2.PathVar) string {
+	var exprs []string
+	literal := ""
+	for i, segment := range v.Segments {
+		if i > 0 {
+			literal += "/"
+		}
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			literal += segment
+			continue
+		}
+		if literal != "" {
+			exprs = append(exprs, strconv.Quote(literal))
+			literal = ""
+		}
+		if segment[0] == '*' {
+			// values of catch-all params start with '/'
+			exprs = append(exprs, fmt.Sprintf("params.ByName(\"%s\")[1:]", segment[1:]))
+		} else {
+			exprs = append(exprs, fmt.Sprintf("params.ByName(\"%s\")", segment[1:]))
+		}
+	}
+	if literal != "" {
+		exprs = append(exprs, strconv.Quote(literal))
+	}
+	return strings.Join(exprs, " + ")
+}
+
+// genBindPathVars binds fields of req from variables of the path, allocating
+// messages along the way to nested fields. It returns dotted paths of the
+// fields bound, which are not bound again from elsewhere.
+func (p *Plugin) genBindPathVars(message *protogen.Message, vars []protoutil2.PathVar, g *genutil.G) (map[string]bool, error) {
+	bound := map[string]bool{}
+	for i, v := range vars {
+		fields, err := v.ResolveFields(message)
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			continue
+		}
+		target := "req"
+		for _, field := range fields[:len(fields)-1] {
+			target += "." + field.GoName
+			g.F("if %s == nil {", target)
+			g.F("%s = &%s{}", target, field.Message.GoIdent)
+			g.P("}")
+		}
+		field := fields[len(fields)-1]
+		target += "." + field.GoName
+		addErr := fmt.Sprintf("errs.Add(%q, \"path\", %q, err)", v.Field, v.Field)
+		if err := p.genConvert(field, paramsSource(v), target, len(message.Fields)+i+1, addErr, g); err != nil {
+			return nil, err
+		}
+		bound[v.Field] = true
+	}
+	return bound, nil
+}
+
+// hasBoundPathVars reports whether any field of message is bound by
+// genBindPathVars.
+func hasBoundPathVars(message *protogen.Message, vars []protoutil2.PathVar) bool {
+	for _, v := range vars {
+		if fields, _ := v.ResolveFields(message); fields != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,6 @@
 package plugin
 
 import (
-	"regexp"
-
 	"github.com/joesonw/proto-tools/pkg/genutil"
 	"google.golang.org/protobuf/compiler/protogen"
 )
@@ -17,10 +15,8 @@ var (
 	pkgIoutil     = protogen.GoImportPath("io/ioutil")
 	pkgProtojson  = protogen.GoImportPath("google.golang.org/protobuf/encoding/protojson")
 	pkgFmt        = protogen.GoImportPath("fmt")
-)
-
-var (
-	pathParamRegex = regexp.MustCompile(`{[a-zA-Z][a-zA-Z0-9_]*}`)
+	pkgStrings    = protogen.GoImportPath("strings")
+	pkgBase64     = protogen.GoImportPath("encoding/base64")
 )
 
 type Plugin struct {
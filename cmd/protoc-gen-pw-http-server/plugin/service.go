@@ -3,6 +3,9 @@ package plugin
 import (
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/joesonw/proto-tools/pkg/genutil"
 	"github.com/joesonw/proto-tools/pkg/protoutil"
@@ -11,6 +14,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 
 	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
+	protoutil2 "github.com/joesonw/proto-web/pkg/protoutil"
 )
 
 type ServiceOptions struct {
@@ -35,7 +39,7 @@ func (p *Plugin) GenService(service *protogen.Service, g *genutil.G) error {
 	}
 
 	for _, method := range service.Methods {
-		if !proto.HasExtension(method.Desc.Options(), openapi_pb.E_Path) {
+		if !proto.HasExtension(method.Desc.Options(), openapi_pb.E_Path) && protoutil2.HttpRule(method) == nil {
 			return fmt.Errorf("method %s of service %s does not have path annotation", method.Desc.Name(), service.Desc.Name())
 		}
 
@@ -61,19 +65,30 @@ func (p *Plugin) GenService(service *protogen.Service, g *genutil.G) error {
 		isServer := method.Desc.IsStreamingServer()
 		isClient := method.Desc.IsStreamingClient()
 		if !isServer && !isClient {
-			httpMethod, path, err := p.getMethodAndPath(method)
+			bindings, err := protoutil2.Bindings(method)
 			if err != nil {
 				return err
 			}
-			if httpMethod == http.MethodConnect {
-				return fmt.Errorf("cannot have stream path for non-stream method")
+			for i, b := range bindings {
+				if b.HttpMethod == http.MethodConnect {
+					return fmt.Errorf("cannot have stream path for non-stream method")
+				}
+				g.P("{")
+				g.F("MethodName: \"%s\",", method.Desc.Name())
+				g.F("Path: \"%s\",", b.Path)
+				g.F("HttpMethod: \"%s\",", b.HttpMethod)
+				if b.Verb != "" {
+					g.F("Verb: \"%s\",", b.Verb)
+				}
+				if b.Status != 0 {
+					g.F("Status: %d,", b.Status)
+				}
+				if b.Location != "" {
+					g.F("Location: %q,", b.Location)
+				}
+				g.F("Handler: %s,", handlerName(method, i))
+				g.P("},")
 			}
-			g.P("{")
-			g.F("MethodName: \"%s\",", method.Desc.Name())
-			g.F("Path: \"%s\",", path)
-			g.F("HttpMethod: \"%s\",", httpMethod)
-			g.F("Handler: _%s_%s_HttpHandler,", service.GoName, method.GoName)
-			g.P("},")
 		}
 	}
 	g.P("},")
@@ -81,14 +96,14 @@ func (p *Plugin) GenService(service *protogen.Service, g *genutil.G) error {
 	for _, method := range service.Methods {
 		isServer := method.Desc.IsStreamingServer()
 		isClient := method.Desc.IsStreamingClient()
-		_, path, err := p.getMethodAndPath(method)
+		bindings, err := protoutil2.Bindings(method)
 		if err != nil {
 			return err
 		}
 		if isServer || isClient {
 			g.P("{")
 			g.F("StreamName: \"%s\",", method.Desc.Name())
-			g.F("Path: \"%s\",", path)
+			g.F("Path: \"%s\",", bindings[0].Path)
 			g.F("Handler: _%s_%s_Handler,", service.GoName, method.GoName)
 			if isServer {
 				g.P("ServerStreams: true,")
@@ -96,6 +111,12 @@ func (p *Plugin) GenService(service *protogen.Service, g *genutil.G) error {
 			if isClient {
 				g.P("ClientStreams: true,")
 			}
+			if !isClient || p.hasBoundParams(method.Input, "*", bindings[0].Vars) {
+				g.F("Binder: _%s_%s_HttpBinder,", service.GoName, method.GoName)
+			}
+			if isDownload(method) {
+				g.F("Chunk: _%s_%s_HttpChunk,", service.GoName, method.GoName)
+			}
 			g.P("},")
 		}
 	}
@@ -110,7 +131,35 @@ func (p *Plugin) validateUnaryRequest(method *protogen.Method, message *protogen
 	return nil
 }
 
-func (p *Plugin) validateUnaryResponse(method *protogen.Method, message *protogen.Message) error {
+var locationVarRe = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// validateUnaryResponse checks message of responses of method served at
+// bindings, which must be google.protobuf.Empty if any of them responds with
+// http.StatusNoContent, as no body is sent. Locations are of
+// http.StatusCreated only, of scalar fields in the response body.
+func (p *Plugin) validateUnaryResponse(method *protogen.Method, message *protogen.Message, bindings []protoutil2.Binding) error {
+	for _, b := range bindings {
+		if b.Status == http.StatusNoContent && message.Desc.FullName() != "google.protobuf.Empty" {
+			return fmt.Errorf("status 204 of method %s of service %s requires google.protobuf.Empty response, not %s", method.Desc.Name(), method.Parent.Desc.Name(), message.Desc.FullName())
+		}
+		if b.Location == "" {
+			continue
+		}
+		if b.Status != http.StatusCreated {
+			return fmt.Errorf("location of method %s of service %s requires status 201, not %d", method.Desc.Name(), method.Parent.Desc.Name(), b.Status)
+		}
+		body := message
+		if field := protoutil2.FindField(message, b.ResponseBody); field != nil && field.Message != nil {
+			body = field.Message
+		}
+		for _, match := range locationVarRe.FindAllStringSubmatch(b.Location, -1) {
+			field := protoutil2.FindField(body, match[1])
+			if field == nil || protoutil2.HasParamAnnotation(field) || field.Desc.IsList() || field.Desc.IsMap() ||
+				field.Desc.Kind() == protoreflect.MessageKind || field.Desc.Kind() == protoreflect.GroupKind || field.Desc.Kind() == protoreflect.BytesKind {
+				return fmt.Errorf("location variable %s of method %s of service %s must be a scalar field in body of %s", match[1], method.Desc.Name(), method.Parent.Desc.Name(), body.Desc.Name())
+			}
+		}
+	}
 	for _, field := range message.Fields {
 		options := field.Desc.Options()
 		if proto.GetExtension(options, openapi_pb.E_InPath).(string) != "" {
@@ -142,115 +191,448 @@ func (p *Plugin) validateStreamMessage(method *protogen.Method, message *protoge
 	return nil
 }
 
-func (p *Plugin) regulatePath(path string) string {
-	indexes := pathParamRegex.FindAllStringIndex(path, -1)
-	result := path
-	offset := 0
-	for _, pair := range indexes {
-		begin := pair[0] - offset
-		end := pair[1] - offset
-		result = result[:begin] + ":" + result[begin+1:end-1] + result[end:]
-		offset += 1
-	}
-	return result
-}
-
-func (p *Plugin) genUnaryRequestHandle(method *protogen.Method, g *genutil.G) error {
-	httpMethod, _, err := p.getMethodAndPath(method)
-	if err != nil {
-		return err
-	}
-	hasBody := httpMethod == http.MethodPost || httpMethod == http.MethodPut
-	if hasBody {
+func (p *Plugin) genUnaryRequestHandle(method *protogen.Method, b protoutil2.Binding, g *genutil.G) error {
+	body := b.Body
+	if body != "" {
+		target := "req"
+		if body != "*" {
+			field := protoutil2.FindField(method.Input, body)
+			if field == nil || field.Desc.Kind() != protoreflect.MessageKind || field.Desc.IsList() || field.Desc.IsMap() {
+				return fmt.Errorf("body %s of method %s must be a message field of %s", body, method.Desc.Name(), method.Input.Desc.Name())
+			}
+			g.F("req.%s = &%s{}", field.GoName, field.Message.GoIdent)
+			target = "req." + field.GoName
+		}
 		g.F("b, err := %s(r.Body)", pkgIoutil.Ident("ReadAll"))
 		g.P("_ = r.Body.Close()")
 		g.P("if err != nil {")
 		g.P("return nil, err")
 		g.P("}")
-		g.F("if err := (%s{}).Unmarshal(b, req); err != nil {", pkgProtojson.Ident("UnmarshalOptions"))
+		g.P("if len(b) > 0 {")
+		g.F("if err := (%s{}).Unmarshal(b, %s); err != nil {", pkgProtojson.Ident("UnmarshalOptions"), target)
 		g.P("return nil, err")
 		g.P("}")
+		g.P("}")
+	}
+	return p.genBindParams(method.Input, body, b.Vars, "return nil, err", g)
+}
+
+// genBindParams binds fields of req from path, query, header and cookie of the
+// request. Fields without annotation are bound from variables of the path, or
+// from query if they can be, with fields of nested messages by their dotted
+// path, unless they are expected from body, which is either "*" for the whole
+// message or the name of a field. Errors of all parameters are returned
+// together by returnErr.
+func (p *Plugin) genBindParams(message *protogen.Message, body string, vars []protoutil2.PathVar, returnErr string, g *genutil.G) error {
+	if !p.hasBoundParams(message, body, vars) {
+		return nil
+	}
+	g.F("var errs %s", pkgProtoWeb.Ident("ParamErrors"))
+	bound, err := p.genBindPathVars(message, vars, g)
+	if err != nil {
+		return err
 	}
-	for i, field := range method.Input.Fields {
+	for i, field := range message.Fields {
 		options := field.Desc.Options()
-		source := ""
-		if proto.HasExtension(options, openapi_pb.E_InQuery) {
-			source = fmt.Sprintf("r.URL.Query().Get(\"%s\")", proto.GetExtension(options, openapi_pb.E_InQuery).(string))
+		in := ""
+		name := ""
+		if body == string(field.Desc.Name()) || bound[string(field.Desc.Name())] {
+			continue
+		} else if proto.HasExtension(options, openapi_pb.E_InQuery) {
+			in, name = "query", proto.GetExtension(options, openapi_pb.E_InQuery).(string)
 		} else if proto.HasExtension(options, openapi_pb.E_InPath) {
-			source = fmt.Sprintf("params.ByName(\"%s\")", proto.GetExtension(options, openapi_pb.E_InPath).(string))
+			in, name = "path", proto.GetExtension(options, openapi_pb.E_InPath).(string)
 		} else if proto.HasExtension(options, openapi_pb.E_InHeader) {
-			source = fmt.Sprintf("r.Header.Get(\"%s\")", proto.GetExtension(options, openapi_pb.E_InHeader).(string))
+			in, name = "header", proto.GetExtension(options, openapi_pb.E_InHeader).(string)
 		} else if proto.HasExtension(options, openapi_pb.E_InCookie) {
-			name := proto.GetExtension(options, openapi_pb.E_InCookie).(string)
-			g.F("c%d, err := r.Cookie(\"%s\")", i+1, name)
-			g.P("if err != nil {")
-			g.P("return nil, err")
-			g.P("}")
-			source = fmt.Sprintf("c%d", i+1)
+			in, name = "cookie", proto.GetExtension(options, openapi_pb.E_InCookie).(string)
+		} else if body != "*" && protoutil2.IsQueryField(field) {
+			in, name = "query", string(field.Desc.Name())
+		} else if body != "*" && protoutil2.IsNestedQueryField(field) {
+			if err := p.genBindNestedParams(message, []*protogen.Field{field}, bound, i+1, g); err != nil {
+				return err
+			}
+			continue
 		} else {
-			if !hasBody {
-				source = proto.GetExtension(options, openapi_pb.E_InQuery).(string)
-			} else {
+			continue
+		}
+		if err := p.genBindParam(field, in, name, nil, fmt.Sprintf("req.%s", field.GoName), i+1, g); err != nil {
+			return err
+		}
+	}
+	g.P("if err := errs.Err(); err != nil {")
+	g.P(returnErr)
+	g.P("}")
+	return nil
+}
+
+// genBindNestedParams binds fields of the message at the end of fields, which
+// are nested message fields from req, from query parameters named by their
+// dotted path, e.g. "page.size", as grpc-gateway does. A recursive message is
+// not bound again within itself.
+func (p *Plugin) genBindNestedParams(root *protogen.Message, fields []*protogen.Field, bound map[string]bool, index int, g *genutil.G) error {
+	var names []string
+	target := "req"
+	for _, field := range fields {
+		names = append(names, string(field.Desc.Name()))
+		target += "." + field.GoName
+	}
+	prefix := strings.Join(names, ".")
+	for _, field := range fields[len(fields)-1].Message.Fields {
+		name := prefix + "." + string(field.Desc.Name())
+		if bound[name] {
+			continue
+		}
+		if protoutil2.IsNestedQueryField(field) {
+			if protoutil2.IsNestedMessage(root, fields, field.Message) {
 				continue
 			}
+			if err := p.genBindNestedParams(root, append(fields[:len(fields):len(fields)], field), bound, index, g); err != nil {
+				return err
+			}
+			continue
 		}
-		checkError, err := p.genConvertFromString(field, source, fmt.Sprintf("req.%s", field.GoName), i+1, g)
-		if err != nil {
+		if field.Desc.IsMap() || !protoutil2.IsQueryField(field) {
+			continue
+		}
+		if err := p.genBindParam(field, "query", name, fields, target+"."+field.GoName, index, g); err != nil {
 			return err
 		}
-		if checkError {
-			g.P("if err != nil {")
-			g.P("return nil, err")
-			g.P("}")
+	}
+	return nil
+}
+
+// genBindParam binds target of field from the parameter name in path, query,
+// header or cookie. Repeated fields are bound from every value of the
+// parameter, or the comma separated ones with style "comma", and always for
+// headers. Maps are bound from query parameters in the form of
+// "name[key]=value". target is left unset if the parameter is absent, and so
+// are messages of parents, which are nested fields from req to target.
+func (p *Plugin) genBindParam(field *protogen.Field, in, name string, parents []*protogen.Field, target string, index int, g *genutil.G) error {
+	path := string(field.Desc.Name())
+	for i := len(parents) - 1; i >= 0; i-- {
+		path = string(parents[i].Desc.Name()) + "." + path
+	}
+	addErr := fmt.Sprintf("errs.Add(%q, %q, %q, err)", path, in, name)
+	if field.Desc.IsMap() {
+		key, value := field.Message.Fields[0], field.Message.Fields[1]
+		if in != "query" || key.Desc.Kind() != protoreflect.StringKind || !protoutil2.IsScalarField(value) {
+			return fmt.Errorf("field %s: only maps of string keys and scalar values can be bound, from query", field.Desc.Name())
+		}
+		g.P("for k, vs := range r.URL.Query() {")
+		g.F("if !%s(k, \"%s[\") || !%s(k, \"]\") {", pkgStrings.Ident("HasPrefix"), name, pkgStrings.Ident("HasSuffix"))
+		g.P("continue")
+		g.P("}")
+		g.F("if %s == nil {", target)
+		g.F("%s = %s{}", target, protoutil.FieldGoType(g.Q, field))
+		g.P("}")
+		g.F("var v %s", protoutil.FieldGoType(g.Q, value))
+		if err := p.genConvert(value, "vs[len(vs)-1]", "v", index, fmt.Sprintf("errs.Add(%q, %q, k, err)", path, in), g); err != nil {
+			return err
+		}
+		g.F("%s[k[%d:len(k)-1]] = v", target, len(name)+1)
+		g.P("}")
+		return nil
+	}
+
+	source, values, present := "", "", ""
+	switch in {
+	case "query":
+		source = fmt.Sprintf("r.URL.Query().Get(\"%s\")", name)
+		values = fmt.Sprintf("r.URL.Query()[\"%s\"]", name)
+		if proto.GetExtension(field.Desc.Options(), openapi_pb.E_Style).(string) == "comma" {
+			values = fmt.Sprintf("%s(%s)", g.Q(pkgProtoWeb.Ident("SplitParams")), values)
+		}
+		present = fmt.Sprintf("_, ok := r.URL.Query()[\"%s\"]; ok", name)
+	case "path":
+		source = fmt.Sprintf("params.ByName(\"%s\")", name)
+	case "header":
+		source = fmt.Sprintf("r.Header.Get(\"%s\")", name)
+		values = fmt.Sprintf("%s(r.Header.Values(\"%s\"))", g.Q(pkgProtoWeb.Ident("SplitParams")), name)
+		present = fmt.Sprintf("len(r.Header.Values(\"%s\")) > 0", name)
+	case "cookie":
+		source = fmt.Sprintf("c%d.Value", index)
+		present = fmt.Sprintf("c%d, err := r.Cookie(\"%s\"); err == nil", index, name)
+	}
+	if field.Desc.IsList() && values == "" {
+		return fmt.Errorf("field %s: repeated fields can only be in query or header", field.Desc.Name())
+	}
+
+	guarded := present != "" && (len(parents) > 0 || !field.Desc.IsList())
+	if guarded {
+		g.F("if %s {", present)
+	}
+	parent := "req"
+	for _, f := range parents {
+		parent += "." + f.GoName
+		g.F("if %s == nil {", parent)
+		g.F("%s = &%s{}", parent, f.Message.GoIdent)
+		g.P("}")
+	}
+	if field.Desc.IsList() {
+		g.F("for _, s := range %s {", values)
+		g.F("var v %s", elemGoType(g, field))
+		if err := p.genConvert(field, "s", "v", index, addErr, g); err != nil {
+			return err
 		}
+		g.F("%s = append(%s, v)", target, target)
+		g.P("}")
+	} else if err := p.genConvert(field, source, target, index, addErr, g); err != nil {
+		return err
 	}
+	if guarded {
+		g.P("}")
+	}
+	return nil
+}
 
+// genConvert converts source to target, with addErr adding the error if any to
+// errs. Optional fields are set to a pointer of the value.
+func (p *Plugin) genConvert(field *protogen.Field, source, target string, index int, addErr string, g *genutil.G) error {
+	value := target
+	optional := field.Desc.HasOptionalKeyword() && field.Desc.Kind() != protoreflect.MessageKind && field.Desc.Kind() != protoreflect.BytesKind
+	if optional {
+		value = fmt.Sprintf("v%d", index)
+		g.F("var %s %s", value, elemGoType(g, field))
+	}
+	checkError, err := p.genConvertFromString(field, source, value, index, g)
+	if err != nil {
+		return err
+	}
+	if checkError {
+		g.P("if err != nil {")
+		g.P(addErr)
+		g.P("}")
+	}
+	if optional {
+		g.F("%s = &%s", target, value)
+	}
 	return nil
 }
 
-func (p *Plugin) genUnaryResponseHandle(method *protogen.Method, g *genutil.G) error {
+// hasBoundParams reports whether any field of message is bound by
+// genBindParams.
+func (p *Plugin) hasBoundParams(message *protogen.Message, body string, vars []protoutil2.PathVar) bool {
+	if hasBoundPathVars(message, vars) {
+		return true
+	}
+	for _, field := range message.Fields {
+		options := field.Desc.Options()
+		if body == string(field.Desc.Name()) {
+			continue
+		}
+		if proto.HasExtension(options, openapi_pb.E_InQuery) ||
+			proto.HasExtension(options, openapi_pb.E_InPath) ||
+			proto.HasExtension(options, openapi_pb.E_InHeader) ||
+			proto.HasExtension(options, openapi_pb.E_InCookie) ||
+			(body != "*" && (protoutil2.IsQueryField(field) || protoutil2.IsNestedQueryField(field))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plugin) genUnaryResponseHandle(method *protogen.Method, b protoutil2.Binding, g *genutil.G) error {
 	for _, field := range method.Output.Fields {
 		options := field.Desc.Options()
 		if proto.HasExtension(options, openapi_pb.E_InQuery) {
-			return fmt.Errorf("field %s of message %s: cannot have in_query annotation in response", field.Desc.Name(), method.Input.Desc.Name())
+			return fmt.Errorf("field %s of message %s: cannot have in_query annotation in response", field.Desc.Name(), method.Output.Desc.Name())
 		} else if proto.HasExtension(options, openapi_pb.E_InPath) {
-			return fmt.Errorf("field %s of message %s: cannot have in_path annotation in response", field.Desc.Name(), method.Input.Desc.Name())
+			return fmt.Errorf("field %s of message %s: cannot have in_path annotation in response", field.Desc.Name(), method.Output.Desc.Name())
 		} else if proto.HasExtension(options, openapi_pb.E_InHeader) {
-			g.F("w.Header().Set(\"%s\", %s(\"%%v\", res.%s))", proto.GetExtension(options, openapi_pb.E_InHeader).(string), pkgFmt.Ident("Sprintf"), field.GoName)
-			g.F("res.%s = %s", field.GoName, field.Desc.Default().String())
+			if err := p.genSetHeader(field, proto.GetExtension(options, openapi_pb.E_InHeader).(string), g); err != nil {
+				return err
+			}
+			g.F("res.%s = %s", field.GoName, zeroValue(field))
 		} else if proto.HasExtension(options, openapi_pb.E_InCookie) {
-			return fmt.Errorf("field %s of message %s: cannot have in_cookie annotation in response", field.Desc.Name(), method.Input.Desc.Name())
+			if err := p.genSetCookie(field, proto.GetExtension(options, openapi_pb.E_InCookie).(string), g); err != nil {
+				return err
+			}
+			g.F("res.%s = %s", field.GoName, zeroValue(field))
 		}
 	}
+	if b.ResponseBody != "" {
+		field := protoutil2.FindField(method.Output, b.ResponseBody)
+		if field == nil || field.Desc.Kind() != protoreflect.MessageKind || field.Desc.IsList() || field.Desc.IsMap() {
+			return fmt.Errorf("response body %s of method %s must be a message field of %s", b.ResponseBody, method.Desc.Name(), method.Output.Desc.Name())
+		}
+		g.F("return res.Get%s(), nil", field.GoName)
+		return nil
+	}
 	g.F("return res, nil")
 	return nil
 }
 
-func (p *Plugin) getMethodAndPath(method *protogen.Method) (string, string, error) {
-	path := proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path)
-	if path.Get != "" {
-		return http.MethodGet, path.Get, nil
-	} else if path.Put != "" {
-		return http.MethodPut, path.Put, nil
-	} else if path.Post != "" {
-		return http.MethodPost, path.Post, nil
-	} else if path.Delete != "" {
-		return http.MethodDelete, path.Delete, nil
-	} else if path.Options != "" {
-		return http.MethodOptions, path.Options, nil
-	} else if path.Head != "" {
-		return http.MethodHead, path.Head, nil
-	} else if path.Patch != "" {
-		return http.MethodPatch, path.Patch, nil
-	} else if path.Trace != "" {
-		return http.MethodTrace, path.Trace, nil
-	} else if path.Stream != "" {
-		return http.MethodConnect, path.Stream, nil
-	} else {
-		return "", "", fmt.Errorf("no valid method found for method %s of service %s", method.Desc.Name(), method.Parent.Desc.Name())
+// handlerName returns the name of the handler of the i-th binding of method.
+func handlerName(method *protogen.Method, i int) string {
+	name := fmt.Sprintf("_%s_%s_HttpHandler", method.Parent.GoName, method.GoName)
+	if i > 0 {
+		name += strconv.Itoa(i)
+	}
+	return name
+}
+
+// genSetHeader sets the response header name from field of res, with a line
+// for each element of repeated fields. Unset messages and optional fields are
+// skipped.
+func (p *Plugin) genSetHeader(field *protogen.Field, name string, g *genutil.G) error {
+	if field.Desc.IsMap() {
+		return fmt.Errorf("field %s: header cannot be a map", field.Desc.Name())
+	}
+	if field.Desc.IsList() {
+		value, err := p.formatValue(field, "v", g)
+		if err != nil {
+			return err
+		}
+		g.F("for _, v := range res.%s {", field.GoName)
+		g.F("w.Header().Add(\"%s\", %s)", name, value)
+		g.P("}")
+		return nil
+	}
+	source := "res." + field.GoName
+	if hasPresence(field) {
+		g.F("if %s != nil {", source)
+		defer g.P("}")
+		if kind := field.Desc.Kind(); kind != protoreflect.MessageKind && kind != protoreflect.BytesKind {
+			source = "(*" + source + ")"
+		}
+	}
+	value, err := p.formatValue(field, source, g)
+	if err != nil {
+		return err
+	}
+	g.F("w.Header().Set(\"%s\", %s)", name, value)
+	return nil
+}
+
+// genSetCookie sets the response cookie name from field of res, with
+// attributes of the cookie option. Unset messages and optional fields are
+// skipped.
+func (p *Plugin) genSetCookie(field *protogen.Field, name string, g *genutil.G) error {
+	if field.Desc.IsList() || field.Desc.IsMap() {
+		return fmt.Errorf("field %s: cookie cannot be repeated", field.Desc.Name())
+	}
+	cookie := proto.GetExtension(field.Desc.Options(), openapi_pb.E_Cookie).(*openapi_pb.Cookie)
+	sameSite := ""
+	switch strings.ToLower(cookie.GetSameSite()) {
+	case "":
+	case "lax":
+		sameSite = "SameSiteLaxMode"
+	case "strict":
+		sameSite = "SameSiteStrictMode"
+	case "none":
+		sameSite = "SameSiteNoneMode"
+	default:
+		return fmt.Errorf("field %s: same_site of cookie must be lax, strict or none", field.Desc.Name())
+	}
+	source := "res." + field.GoName
+	if hasPresence(field) {
+		g.F("if %s != nil {", source)
+		defer g.P("}")
+		if kind := field.Desc.Kind(); kind != protoreflect.MessageKind && kind != protoreflect.BytesKind {
+			source = "(*" + source + ")"
+		}
+	}
+	value, err := p.formatValue(field, source, g)
+	if err != nil {
+		return err
+	}
+	g.F("%s(w, &%s{", pkgHttp.Ident("SetCookie"), pkgHttp.Ident("Cookie"))
+	g.F("Name: \"%s\",", name)
+	g.F("Value: %s,", value)
+	if cookie.GetPath() != "" {
+		g.F("Path: %q,", cookie.GetPath())
+	}
+	if cookie.GetDomain() != "" {
+		g.F("Domain: %q,", cookie.GetDomain())
+	}
+	if cookie.GetMaxAge() != 0 {
+		g.F("MaxAge: %d,", cookie.GetMaxAge())
+	}
+	if cookie.GetSecure() {
+		g.P("Secure: true,")
+	}
+	if cookie.GetHttpOnly() {
+		g.P("HttpOnly: true,")
+	}
+	if sameSite != "" {
+		g.F("SameSite: %s,", pkgHttp.Ident(sameSite))
+	}
+	g.P("})")
+	return nil
+}
+
+// formatValue returns the expression formatting value of field, or of an
+// element of it if it's repeated, as a string of a header or cookie.
+// Timestamps are formatted as HTTP dates, and other well known types in their
+// JSON string form.
+func (p *Plugin) formatValue(field *protogen.Field, value string, g *genutil.G) (string, error) {
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return value, nil
+	case protoreflect.BoolKind:
+		return fmt.Sprintf("%s(%s)", g.Q(pkgStrconv.Ident("FormatBool")), value), nil
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("%s.EncodeToString(%s)", g.Q(pkgBase64.Ident("StdEncoding")), value), nil
+	case protoreflect.EnumKind:
+		return value + ".String()", nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return fmt.Sprintf("%s(int64(%s), 10)", g.Q(pkgStrconv.Ident("FormatInt")), value), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return fmt.Sprintf("%s(%s, 10)", g.Q(pkgStrconv.Ident("FormatInt")), value), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return fmt.Sprintf("%s(uint64(%s), 10)", g.Q(pkgStrconv.Ident("FormatUint")), value), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return fmt.Sprintf("%s(%s, 10)", g.Q(pkgStrconv.Ident("FormatUint")), value), nil
+	case protoreflect.FloatKind:
+		return fmt.Sprintf("%s(float64(%s), 'g', -1, 32)", g.Q(pkgStrconv.Ident("FormatFloat")), value), nil
+	case protoreflect.DoubleKind:
+		return fmt.Sprintf("%s(%s, 'g', -1, 64)", g.Q(pkgStrconv.Ident("FormatFloat")), value), nil
+	case protoreflect.MessageKind:
+		if field.Message.Desc.FullName() == "google.protobuf.Timestamp" {
+			return fmt.Sprintf("%s.AsTime().Format(%s)", value, g.Q(pkgHttp.Ident("TimeFormat"))), nil
+		} else if protoutil2.IsWellKnownParam(field.Message) {
+			return fmt.Sprintf("%s(%s)", g.Q(pkgProtoWeb.Ident("FormatParam")), value), nil
+		}
+	}
+	return "", fmt.Errorf("field %s(type %s) cannot be formatted as string", field.Desc.Name(), field.Desc.Kind().String())
+}
+
+// hasPresence reports whether field is a message or an optional field, which
+// is nil if it's unset.
+func hasPresence(field *protogen.Field) bool {
+	return !field.Desc.IsList() && !field.Desc.IsMap() &&
+		(field.Desc.Kind() == protoreflect.MessageKind || field.Desc.HasOptionalKeyword())
+}
+
+// zeroValue returns the Go zero value of field.
+func zeroValue(field *protogen.Field) string {
+	if field.Desc.IsList() || field.Desc.IsMap() || hasPresence(field) {
+		return "nil"
+	}
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return `""`
+	case protoreflect.BoolKind:
+		return "false"
+	case protoreflect.BytesKind:
+		return "nil"
+	}
+	return "0"
+}
+
+// elemGoType returns the Go type of elements of field if it's repeated, or of
+// field itself.
+func elemGoType(g *genutil.G, field *protogen.Field) string {
+	if field.Desc.IsList() {
+		return strings.TrimPrefix(protoutil.FieldGoType(g.Q, field), "[]")
 	}
+	return protoutil.FieldGoType(g.Q, field)
 }
 
+// genConvertFromString converts source to target by the kind of field, and
+// reports whether err is to be checked. Numbers are parsed within the range of
+// their kinds, bytes are in base64 and enums are either names or numbers.
 func (p *Plugin) genConvertFromString(field *protogen.Field, source, target string, index int, g *genutil.G) (bool, error) {
 	switch field.Desc.Kind() {
 	case protoreflect.StringKind:
@@ -259,19 +641,40 @@ func (p *Plugin) genConvertFromString(field *protogen.Field, source, target stri
 	case protoreflect.BoolKind:
 		g.F("%s, err = %s(%s)", target, pkgStrconv.Ident("ParseBool"), source)
 		return true, nil
+	case protoreflect.BytesKind:
+		g.F("%s, err = %s(%s)", target, pkgProtoWeb.Ident("ParseBytes"), source)
+		return true, nil
 	case protoreflect.EnumKind:
-		g.F("%s = %s(%s_value[%s])", target, field.Enum.GoIdent, field.Enum.GoIdent, source)
-		return false, nil
-	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
-		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
-		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
-		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
-		g.F("x%d, err := %s(%s, 10, 64)", index, pkgStrconv.Ident("ParseInt"), source)
-		g.F("%s = %s(x%d)", target, protoutil.FieldGoType(g.Q, field), index)
+		g.F("x%d, err := %s(%s, %s_value)", index, pkgProtoWeb.Ident("ParseEnum"), source, field.Enum.GoIdent)
+		g.F("%s = %s(x%d)", target, field.Enum.GoIdent, index)
+		return true, nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		g.F("x%d, err := %s(%s, 10, 32)", index, pkgStrconv.Ident("ParseInt"), source)
+		g.F("%s = int32(x%d)", target, index)
+		return true, nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		g.F("%s, err = %s(%s, 10, 64)", target, pkgStrconv.Ident("ParseInt"), source)
+		return true, nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		g.F("x%d, err := %s(%s, 10, 32)", index, pkgStrconv.Ident("ParseUint"), source)
+		g.F("%s = uint32(x%d)", target, index)
 		return true, nil
-	case protoreflect.FloatKind, protoreflect.DoubleKind:
-		g.F("x%d, err := %s(%s,  64)", index, pkgStrconv.Ident("ParseFloat"), source)
-		g.F("%s = %s(x%d)", target, protoutil.FieldGoType(g.Q, field), index)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		g.F("%s, err = %s(%s, 10, 64)", target, pkgStrconv.Ident("ParseUint"), source)
+		return true, nil
+	case protoreflect.FloatKind:
+		g.F("x%d, err := %s(%s, 32)", index, pkgStrconv.Ident("ParseFloat"), source)
+		g.F("%s = float32(x%d)", target, index)
+		return true, nil
+	case protoreflect.DoubleKind:
+		g.F("%s, err = %s(%s, 64)", target, pkgStrconv.Ident("ParseFloat"), source)
+		return true, nil
+	case protoreflect.MessageKind:
+		if !protoutil2.IsWellKnownParam(field.Message) {
+			return false, fmt.Errorf("field %s(type %s) cannot be casted from string", field.Desc.Name(), field.Message.Desc.FullName())
+		}
+		g.F("%s = &%s{}", target, field.Message.GoIdent)
+		g.F("err = %s(%s, %s)", pkgProtoWeb.Ident("UnmarshalParam"), source, target)
 		return true, nil
 	default:
 		return false, fmt.Errorf("field %s(type %s) cannot be casted from string", field.Desc.Name(), field.Desc.Kind().String())
@@ -1,10 +1,108 @@
 package plugin
 
 import (
+	"fmt"
+
 	"github.com/joesonw/proto-tools/pkg/genutil"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
+	protoutil2 "github.com/joesonw/proto-web/pkg/protoutil"
 )
 
 func (p *Plugin) GenStream(method *protogen.Method, options ServiceOptions, g *genutil.G) error {
+	if isDownload(method) {
+		if err := p.genDownloadChunk(method, g); err != nil {
+			return err
+		}
+	} else if err := p.validateStreamMessage(method, method.Output); err != nil {
+		return err
+	}
+
+	// requests of server-only streams are entirely bound from the upgrade
+	// request, client messages are bound on top of what is received.
+	bindings, err := protoutil2.Bindings(method)
+	if err != nil {
+		return err
+	}
+	if len(bindings) > 1 {
+		return fmt.Errorf("stream method %s of service %s cannot have additional bindings", method.Desc.Name(), method.Parent.Desc.Name())
+	}
+	vars := bindings[0].Vars
+	body := ""
+	isClient := method.Desc.IsStreamingClient()
+	if isClient {
+		body = "*"
+	}
+	hasBoundParams := p.hasBoundParams(method.Input, body, vars)
+	if isClient && !hasBoundParams {
+		return nil
+	}
+
+	g.F("func _%s_%s_HttpBinder(m interface{}, r *%s, params %s) (err error) {", method.Parent.GoName, method.GoName, pkgHttp.Ident("Request"), pkgHttpRouter.Ident("Params"))
+	if hasBoundParams {
+		g.F("req := m.(*%s)", method.Input.GoIdent)
+	}
+	if err := p.genBindParams(method.Input, body, vars, "return err", g); err != nil {
+		return err
+	}
+	g.P("return nil")
+	g.P("}")
+	return nil
+}
+
+// isDownload reports whether method is served as a raw chunked response.
+func isDownload(method *protogen.Method) bool {
+	return proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path).GetDownload()
+}
+
+// genDownloadChunk generates the function returning the bytes of a chunk of a
+// download, and setting response headers from in_header fields of the first
+// one.
+func (p *Plugin) genDownloadChunk(method *protogen.Method, g *genutil.G) error {
+	if !method.Desc.IsStreamingServer() || method.Desc.IsStreamingClient() {
+		return fmt.Errorf("method %s of service %s: download must be a server-only stream", method.Desc.Name(), method.Parent.Desc.Name())
+	}
+
+	var data *protogen.Field
+	var headers []*protogen.Field
+	for _, field := range method.Output.Fields {
+		options := field.Desc.Options()
+		if proto.HasExtension(options, openapi_pb.E_InHeader) {
+			if field.Desc.Kind() != protoreflect.StringKind || field.Desc.IsList() {
+				return fmt.Errorf("field %s of message %s: header of download must be a string", field.Desc.Name(), method.Output.Desc.Name())
+			}
+			headers = append(headers, field)
+			continue
+		}
+		if proto.HasExtension(options, openapi_pb.E_InPath) || proto.HasExtension(options, openapi_pb.E_InQuery) || proto.HasExtension(options, openapi_pb.E_InCookie) {
+			return fmt.Errorf("field %s of message %s cannot be in path, query or cookie, because it's a download chunk", field.Desc.Name(), method.Output.Desc.Name())
+		}
+		if field.Desc.Kind() == protoreflect.BytesKind && !field.Desc.IsList() {
+			if data != nil {
+				return fmt.Errorf("message %s: download chunk must have exactly one bytes field", method.Output.Desc.Name())
+			}
+			data = field
+		}
+	}
+	if data == nil {
+		return fmt.Errorf("message %s: download chunk must have exactly one bytes field", method.Output.Desc.Name())
+	}
+
+	g.F("func _%s_%s_HttpChunk(m interface{}, h %s) []byte {", method.Parent.GoName, method.GoName, pkgHttp.Ident("Header"))
+	g.F("res := m.(*%s)", method.Output.GoIdent)
+	if len(headers) > 0 {
+		g.P("if h != nil {")
+		for _, field := range headers {
+			g.F("if res.%s != \"\" {", field.GoName)
+			g.F("h.Set(\"%s\", res.%s)", proto.GetExtension(field.Desc.Options(), openapi_pb.E_InHeader).(string), field.GoName)
+			g.P("}")
+		}
+		g.P("}")
+	}
+	g.F("return res.%s", data.GoName)
+	g.P("}")
 	return nil
 }
@@ -3,19 +3,39 @@ package plugin
 import (
 	"github.com/joesonw/proto-tools/pkg/genutil"
 	"google.golang.org/protobuf/compiler/protogen"
+
+	protoutil2 "github.com/joesonw/proto-web/pkg/protoutil"
 )
 
+// GenUnary generates a handler for every binding of method, which differ in
+// how the request is bound.
 func (p *Plugin) GenUnary(method *protogen.Method, options ServiceOptions, g *genutil.G) error {
-	g.F("func _%s_%s_HttpHandler(srv interface{}, w %s, r *%s, params %s, interceptor %s) (interface{}, error) {", method.Parent.GoName, method.GoName, pkgHttp.Ident("ResponseWriter"), pkgHttp.Ident("Request"), pkgHttpRouter.Ident("Params"), pkgGrpc.Ident("UnaryServerInterceptor"))
+	bindings, err := protoutil2.Bindings(method)
+	if err != nil {
+		return err
+	}
+	if err := p.validateUnaryResponse(method, method.Output, bindings); err != nil {
+		return err
+	}
+	for i, b := range bindings {
+		if err := p.genUnaryHandler(method, handlerName(method, i), b, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) genUnaryHandler(method *protogen.Method, name string, b protoutil2.Binding, g *genutil.G) error {
+	g.F("func %s(srv interface{}, w %s, r *%s, params %s, interceptor %s) (interface{}, error) {", name, pkgHttp.Ident("ResponseWriter"), pkgHttp.Ident("Request"), pkgHttpRouter.Ident("Params"), pkgGrpc.Ident("UnaryServerInterceptor"))
 	g.P("var err error")
 	g.P("ctx := r.Context()")
 	g.F("req := &%s{}", method.Input.GoIdent)
-	if err := p.genUnaryRequestHandle(method, g); err != nil {
+	if err := p.genUnaryRequestHandle(method, b, g); err != nil {
 		return err
 	}
 
 	g.F("var res *%s", method.Output.GoIdent)
-	g.P("if interceptor != nil {")
+	g.P("if interceptor == nil {")
 	g.F("res, err = srv.(%sServer).%s(ctx, req)", method.Parent.GoName, method.GoName)
 	g.P("} else {")
 	g.F("info := &%s{", pkgGrpc.Ident("UnaryServerInfo"))
@@ -29,10 +49,13 @@ func (p *Plugin) GenUnary(method *protogen.Method, options ServiceOptions, g *ge
 	g.F("}")
 	g.P("var resp interface{}")
 	g.P("resp, err = interceptor(ctx, req, info, handler)")
-	g.F("res = resp.(*%s)", method.Output.GoIdent)
+	g.F("res, _ = resp.(*%s)", method.Output.GoIdent)
+	g.P("}")
+	g.P("if err != nil {")
+	g.P("return nil, err")
 	g.P("}")
 
-	if err := p.genUnaryResponseHandle(method, g); err != nil {
+	if err := p.genUnaryResponseHandle(method, b, g); err != nil {
 		return err
 	}
 	g.P("}")
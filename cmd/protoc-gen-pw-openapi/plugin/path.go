@@ -3,12 +3,14 @@ package plugin
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
 
 	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
+	"github.com/joesonw/proto-web/pkg/protoutil"
 )
 
 func (p *Plugin) genFile(file *protogen.File) (map[string]H, H, error) {
@@ -38,49 +40,132 @@ func (p *Plugin) genService(service *protogen.Service) (map[string]H, H, error)
 	paths := map[string]H{}
 	channels := H{}
 	for _, method := range service.Methods {
-		httpMethod, path, err := getMethodAndPath(method)
+		bindings, err := protoutil.Bindings(method)
 		if err != nil {
 			return nil, nil, err
 		}
-		if method.Desc.IsStreamingServer() || method.Desc.IsStreamingClient() {
-			h, err := p.genWebSocketMethod(method)
+		path := bindings[0].OpenAPIPath
+		if proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path).GetDownload() {
+			h, err := p.genDownloadMethod(method)
 			if err != nil {
 				return nil, nil, err
 			}
-			channels[prefix+path] = h
-		} else {
-			if httpMethod == http.MethodConnect {
-				return nil, nil, fmt.Errorf("no stream allowed for unary method")
+			if _, ok := paths[prefix+path]; !ok {
+				paths[prefix+path] = H{}
 			}
-			h, err := p.genHttpMethod(method)
+			paths[prefix+path]["get"] = h
+		} else if method.Desc.IsStreamingServer() || method.Desc.IsStreamingClient() {
+			h, err := p.genWebSocketMethod(method)
 			if err != nil {
 				return nil, nil, err
 			}
-			paths[prefix+path] = H{
-				strings.ToLower(httpMethod): h,
+			channels[prefix+path] = h
+		} else {
+			for _, b := range bindings {
+				if b.HttpMethod == http.MethodConnect {
+					return nil, nil, fmt.Errorf("no stream allowed for unary method")
+				}
+				h, err := p.genHttpMethod(method, b)
+				if err != nil {
+					return nil, nil, err
+				}
+				if _, ok := paths[prefix+b.OpenAPIPath]; !ok {
+					paths[prefix+b.OpenAPIPath] = H{}
+				}
+				paths[prefix+b.OpenAPIPath][strings.ToLower(b.HttpMethod)] = h
 			}
 		}
 	}
 	return paths, channels, nil
 }
 
-func (p *Plugin) genHttpMethod(method *protogen.Method) (H, error) {
-	path := proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path)
-	httpMethod, _, err := getMethodAndPath(method)
+func (p *Plugin) genHttpMethod(method *protogen.Method, b protoutil.Binding) (H, error) {
+	h := H{}
+	h["description"] = commentSetToString(method.Comments)
+	h["summary"] = b.Summary
+	h["operationId"] = b.ID
+	parameters, requestBody, err := p.messageToRequest(b.Body, b.Vars, method.Input)
 	if err != nil {
 		return nil, err
 	}
+	h["parameters"] = parameters
+	h["requestBody"] = requestBody
+	output := method.Output
+	if b.ResponseBody != "" {
+		field := protoutil.FindField(output, b.ResponseBody)
+		if field == nil || field.Message == nil || field.Desc.IsList() || field.Desc.IsMap() {
+			return nil, fmt.Errorf("response body %s of method %s must be a message field of %s", b.ResponseBody, method.Desc.Name(), output.Desc.Name())
+		}
+		output = field.Message
+	}
+	response := p.messageToResponse(output)
+	code := "default"
+	if b.Status != 0 {
+		code = strconv.Itoa(b.Status)
+	}
+	if b.Status == http.StatusNoContent {
+		delete(response, "content")
+	}
+	if b.Location != "" {
+		response["headers"].(H)["Location"] = H{
+			"description": "Location of the created resource, " + b.Location,
+			"schema": H{
+				"type":   "string",
+				"format": "uri-reference",
+			},
+		}
+	}
+	h["responses"] = H{
+		code: response,
+	}
+
+	return h, nil
+}
+
+// genDownloadMethod documents a server stream of chunks served as a raw
+// response, headers are set from in_header fields of the first chunk.
+func (p *Plugin) genDownloadMethod(method *protogen.Method) (H, error) {
+	path := proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path)
 	h := H{}
 	h["description"] = commentSetToString(method.Comments)
 	h["summary"] = path.GetSummary()
 	h["operationId"] = path.GetId()
-	parameters, requestBody := p.messageToRequest(httpMethod == http.MethodPost || httpMethod == http.MethodPut || httpMethod == http.MethodPatch, method.Input)
+	bindings, err := protoutil.Bindings(method)
+	if err != nil {
+		return nil, err
+	}
+	if len(bindings) == 0 {
+		return nil, fmt.Errorf("no valid method found for method %s of service %s", method.Desc.Name(), method.Parent.Desc.Name())
+	}
+	parameters, _, err := p.messageToRequest("", bindings[0].Vars, method.Input)
+	if err != nil {
+		return nil, err
+	}
 	h["parameters"] = parameters
-	h["requestBody"] = requestBody
+	headers := H{}
+	for _, field := range method.Output.Fields {
+		options := field.Desc.Options()
+		if proto.HasExtension(options, openapi_pb.E_InHeader) {
+			headers[proto.GetExtension(options, openapi_pb.E_InHeader).(string)] = H{
+				"description": commentSetToString(field.Comments),
+				"schema":      p.fieldToSchema(field),
+			}
+		}
+	}
 	h["responses"] = H{
-		"default": p.messageToResponse(method.Output),
+		"default": H{
+			"description": commentSetToString(method.Output.Comments),
+			"content": H{
+				"application/octet-stream": H{
+					"schema": H{
+						"type":   "string",
+						"format": "binary",
+					},
+				},
+			},
+			"headers": headers,
+		},
 	}
-
 	return h, nil
 }
 
@@ -98,28 +183,3 @@ func (p *Plugin) genWebSocketMethod(method *protogen.Method) (H, error) {
 	}
 	return h, nil
 }
-
-func getMethodAndPath(method *protogen.Method) (string, string, error) {
-	path := proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path)
-	if path.Get != "" {
-		return http.MethodGet, path.Get, nil
-	} else if path.Put != "" {
-		return http.MethodPut, path.Put, nil
-	} else if path.Post != "" {
-		return http.MethodPost, path.Post, nil
-	} else if path.Delete != "" {
-		return http.MethodDelete, path.Delete, nil
-	} else if path.Options != "" {
-		return http.MethodOptions, path.Options, nil
-	} else if path.Head != "" {
-		return http.MethodHead, path.Head, nil
-	} else if path.Patch != "" {
-		return http.MethodPatch, path.Patch, nil
-	} else if path.Trace != "" {
-		return http.MethodTrace, path.Trace, nil
-	} else if path.Stream != "" {
-		return http.MethodConnect, path.Stream, nil
-	} else {
-		return "", "", fmt.Errorf("no valid method found for method %s of service %s", method.Desc.Name(), method.Parent.Desc.Name())
-	}
-}
@@ -2,7 +2,6 @@ package plugin
 
 import (
 	"encoding/json"
-	"regexp"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
@@ -11,10 +10,6 @@ import (
 	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
 )
 
-var (
-	pathParamRegex = regexp.MustCompile(`{[a-zA-Z][a-zA-Z0-9_]*}`)
-)
-
 type H map[string]interface{}
 
 type Plugin struct {
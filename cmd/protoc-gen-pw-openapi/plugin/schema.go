@@ -2,21 +2,59 @@ package plugin
 
 import (
 	"fmt"
+	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 
 	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
+	"github.com/joesonw/proto-web/pkg/protoutil"
 )
 
-func (p *Plugin) messageToRequest(hasBody bool, message *protogen.Message) (parameters []H, requestBody H) {
+// messageToRequest documents fields of message as parameters and request body.
+// body is either "*" for the whole message, the name of a field, or "" for no
+// body at all. Fields without annotation are path parameters if they are bound
+// from variables of the path, which may be nested, or else query parameters,
+// with fields of nested messages by their dotted path.
+func (p *Plugin) messageToRequest(body string, vars []protoutil.PathVar, message *protogen.Message) (parameters []H, requestBody H, err error) {
+	bound := map[string]bool{}
+	for _, v := range vars {
+		fields, err := v.ResolveFields(message)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fields == nil {
+			continue
+		}
+		field := fields[len(fields)-1]
+		schema := p.fieldToSchema(field)
+		if v.Pattern != "" {
+			schema["pattern"] = v.Pattern
+		}
+		parameters = append(parameters, H{
+			"name":            v.Field,
+			"in":              "path",
+			"required":        true,
+			"deprecated":      proto.GetExtension(field.Desc.Options(), openapi_pb.E_Deprecated).(bool),
+			"allowEmptyValue": false,
+			"description":     commentSetToString(field.Comments),
+			"schema":          schema,
+		})
+		bound[v.Field] = true
+	}
+
 	schema := H{}
+	var bodyField *protogen.Field
 	for _, field := range message.Fields {
 		options := field.Desc.Options()
 		name := ""
 		in := ""
-		if proto.HasExtension(options, openapi_pb.E_InHeader) {
+		if bound[string(field.Desc.Name())] {
+			continue
+		} else if body == string(field.Desc.Name()) {
+			bodyField = field
+		} else if proto.HasExtension(options, openapi_pb.E_InHeader) {
 			name = proto.GetExtension(options, openapi_pb.E_InHeader).(string)
 			in = "header"
 		} else if proto.HasExtension(options, openapi_pb.E_InPath) {
@@ -25,27 +63,36 @@ func (p *Plugin) messageToRequest(hasBody bool, message *protogen.Message) (para
 		} else if proto.HasExtension(options, openapi_pb.E_InCookie) {
 			name = proto.GetExtension(options, openapi_pb.E_InCookie).(string)
 			in = "cookie"
-		} else if proto.HasExtension(options, openapi_pb.E_InQuery) || !hasBody {
+		} else if proto.HasExtension(options, openapi_pb.E_InQuery) {
 			name = proto.GetExtension(options, openapi_pb.E_InQuery).(string)
 			in = "query"
-		} else {
+		} else if body != "*" && protoutil.IsNestedQueryField(field) {
+			parameters = append(parameters, p.nestedQueryParameters(message, []*protogen.Field{field}, bound)...)
+		} else if body != "*" && protoutil.IsQueryField(field) {
+			name = string(field.Desc.Name())
+			in = "query"
+		} else if body == "*" {
 			schema[string(field.Desc.Name())] = p.fieldToSchema(field)
 		}
 		if in == "" {
 			continue
 		}
-		parameters = append(parameters, H{
-			"name":            name,
-			"in":              in,
-			"required":        proto.GetExtension(options, openapi_pb.E_Required).(bool),
-			"deprecated":      proto.GetExtension(options, openapi_pb.E_Deprecated).(bool),
-			"allowEmptyValue": proto.GetExtension(options, openapi_pb.E_AllowEmptyValue).(bool),
-			"description":     commentSetToString(field.Comments),
-			"schema":          p.fieldToSchema(field),
-		})
+		parameters = append(parameters, p.fieldToParameter(field, name, in))
 	}
 
-	if hasBody {
+	if body != "" && body != "*" {
+		if bodyField == nil || bodyField.Message == nil || bodyField.Desc.IsList() || bodyField.Desc.IsMap() {
+			return nil, nil, fmt.Errorf("body %s must be a message field of %s", body, message.Desc.Name())
+		}
+		requestBody = H{
+			"content": H{
+				"application/json": H{
+					"schema": p.messageToSchema(bodyField.Message),
+				},
+			},
+			"description": commentSetToString(bodyField.Comments),
+		}
+	} else if body == "*" {
 		requestBody = H{
 			"content": H{
 				"application/json": H{
@@ -61,23 +108,124 @@ func (p *Plugin) messageToRequest(hasBody bool, message *protogen.Message) (para
 	return
 }
 
+// nestedQueryParameters documents fields of the message at the end of fields,
+// which are nested message fields from the request, as query parameters named
+// by their dotted path, e.g. "page.size". A recursive message is not documented
+// again within itself.
+func (p *Plugin) nestedQueryParameters(root *protogen.Message, fields []*protogen.Field, bound map[string]bool) []H {
+	var names []string
+	for _, field := range fields {
+		names = append(names, string(field.Desc.Name()))
+	}
+	prefix := strings.Join(names, ".")
+	var parameters []H
+	for _, field := range fields[len(fields)-1].Message.Fields {
+		name := prefix + "." + string(field.Desc.Name())
+		if bound[name] {
+			continue
+		}
+		if protoutil.IsNestedQueryField(field) {
+			if !protoutil.IsNestedMessage(root, fields, field.Message) {
+				parameters = append(parameters, p.nestedQueryParameters(root, append(fields[:len(fields):len(fields)], field), bound)...)
+			}
+		} else if !field.Desc.IsMap() && protoutil.IsQueryField(field) {
+			parameters = append(parameters, p.fieldToParameter(field, name, "query"))
+		}
+	}
+	return parameters
+}
+
+// wellKnownSchema returns the schema of the JSON form of message if it's a well
+// known type which can be a parameter, or nil otherwise.
+func wellKnownSchema(message *protogen.Message) H {
+	switch message.Desc.FullName() {
+	case "google.protobuf.Timestamp":
+		return H{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration", "google.protobuf.FieldMask", "google.protobuf.StringValue":
+		return H{"type": "string"}
+	case "google.protobuf.BytesValue":
+		return H{"type": "string", "format": "byte"}
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue":
+		return H{"type": "number"}
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return H{"type": "integer"}
+	case "google.protobuf.BoolValue":
+		return H{"type": "boolean"}
+	}
+	return nil
+}
+
+func (p *Plugin) fieldToParameter(field *protogen.Field, name, in string) H {
+	options := field.Desc.Options()
+	parameter := H{
+		"name":            name,
+		"in":              in,
+		"required":        proto.GetExtension(options, openapi_pb.E_Required).(bool),
+		"deprecated":      proto.GetExtension(options, openapi_pb.E_Deprecated).(bool),
+		"allowEmptyValue": proto.GetExtension(options, openapi_pb.E_AllowEmptyValue).(bool),
+		"description":     commentSetToString(field.Comments),
+		"schema":          p.fieldToSchema(field),
+	}
+	setParameterStyle(parameter, field, in)
+	return parameter
+}
+
+// setParameterStyle sets style and explode of parameter, for how repeated and
+// map fields are bound. Repeated query parameters are either repeated, or
+// comma separated with style "comma", and repeated headers are comma separated.
+// Maps are in the form of "name[key]=value".
+func setParameterStyle(parameter H, field *protogen.Field, in string) {
+	if field.Desc.IsMap() {
+		parameter["style"] = "deepObject"
+		parameter["explode"] = true
+	} else if field.Desc.IsList() && in == "header" {
+		parameter["style"] = "simple"
+		parameter["explode"] = false
+	} else if field.Desc.IsList() {
+		parameter["style"] = "form"
+		parameter["explode"] = proto.GetExtension(field.Desc.Options(), openapi_pb.E_Style).(string) != "comma"
+	}
+}
+
+// messageToResponse documents fields of message as the response body, or as
+// headers if they are in_header, where timestamps are HTTP dates, or as the
+// Set-Cookie header if they are in_cookie.
 func (p *Plugin) messageToResponse(message *protogen.Message) H {
 	schema := H{}
 	headers := H{}
+	var cookies []string
 	for _, field := range message.Fields {
 		options := field.Desc.Options()
 		if proto.HasExtension(options, openapi_pb.E_InHeader) {
+			headerSchema := p.fieldToSchema(field)
+			if field.Message != nil && field.Message.Desc.FullName() == "google.protobuf.Timestamp" {
+				if field.Desc.IsList() {
+					headerSchema["items"].(H)["format"] = "http-date"
+				} else {
+					headerSchema["format"] = "http-date"
+				}
+			}
 			headers[proto.GetExtension(options, openapi_pb.E_InHeader).(string)] = H{
 				"description": commentSetToString(field.Comments),
-				"schema":      p.fieldToSchema(field),
+				"schema":      headerSchema,
 			}
 		} else if proto.HasExtension(options, openapi_pb.E_InPath) {
 		} else if proto.HasExtension(options, openapi_pb.E_InCookie) {
+			cookies = append(cookies, proto.GetExtension(options, openapi_pb.E_InCookie).(string))
 		} else if proto.HasExtension(options, openapi_pb.E_InQuery) {
 		} else {
 			schema[string(field.Desc.Name())] = p.fieldToSchema(field)
 		}
 	}
+	if len(cookies) > 0 {
+		headers["Set-Cookie"] = H{
+			"description": "Sets cookies " + strings.Join(cookies, ", "),
+			"schema": H{
+				"type": "string",
+			},
+		}
+	}
 
 	return H{
 		"description": commentSetToString(message.Comments),
@@ -155,9 +303,20 @@ func (p *Plugin) fieldToSchema(field *protogen.Field) H {
 			"description": commentSetToString(field.Comments),
 		}
 	case protoreflect.BytesKind:
-		panic(fmt.Sprintf("field type %s is not currently supported, please use %s", protoreflect.BytesKind.String(), protoreflect.StringKind.String()))
+		output = H{
+			"type":        "string",
+			"format":      "byte",
+			"description": commentSetToString(field.Comments),
+		}
 	case protoreflect.MessageKind:
-		output = p.messageToSchema(field.Message)
+		if field.Desc.IsMap() {
+			output = p.fieldToSchema(field.Message.Fields[1])
+		} else if schema := wellKnownSchema(field.Message); schema != nil {
+			output = schema
+			output["description"] = commentSetToString(field.Comments)
+		} else {
+			output = p.messageToSchema(field.Message)
+		}
 	default:
 		panic(fmt.Sprintf("field type %s is not currently supported", field.Desc.Kind().String()))
 	}
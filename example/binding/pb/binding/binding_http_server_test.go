@@ -0,0 +1,180 @@
+package pbgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/joesonw/proto-web/pkg/protoweb"
+)
+
+type testBindingServer struct {
+	UnimplementedBindingServer
+}
+
+func (testBindingServer) GetBook(ctx context.Context, req *Request) (*Response, error) {
+	return &Response{Request: req}, nil
+}
+
+func (testBindingServer) UpdateBook(ctx context.Context, req *Request) (*Response, error) {
+	return &Response{Request: req}, nil
+}
+
+func (testBindingServer) GetFile(ctx context.Context, req *Request) (*Response, error) {
+	return &Response{Request: req}, nil
+}
+
+// serve serves r by the generated handlers, without interceptor.
+func serve(r *http.Request) *httptest.ResponseRecorder {
+	s := protoweb.NewServer()
+	RegisterBindingHTTPServer(s, testBindingServer{})
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// bound returns the request echoed by a successful response.
+func bound(t *testing.T, w *httptest.ResponseRecorder) *Request {
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	res := &Response{}
+	if err := protojson.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatal(err)
+	}
+	return res.Request
+}
+
+func TestBindParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/shelves/1/books/2?book.title=t&count=0&tags=a&tags=b&labels[x]=1&labels[y]=2&time=2006-01-02T15:04:05Z&c=BLUE&data=aGk", nil)
+	r.Header.Set("X-Trace", "trace")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+	count := int32(0)
+	want := &Request{
+		Book:    &Book{Name: "shelves/1/books/2", Title: "t"},
+		Count:   &count,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]int32{"x": 1, "y": 2},
+		Time:    timestamppb.New(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)),
+		C:       Request_BLUE,
+		Data:    []byte("hi"),
+		Trace:   "trace",
+		Session: "s",
+	}
+	if req := bound(t, serve(r)); !proto.Equal(req, want) {
+		t.Errorf("request = %v, want %v", req, want)
+	}
+}
+
+func TestBindAbsentParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/shelves/1/books/2", nil)
+	want := &Request{
+		Book: &Book{Name: "shelves/1/books/2"},
+	}
+	if req := bound(t, serve(r)); !proto.Equal(req, want) {
+		t.Errorf("request = %v, want %v", req, want)
+	}
+}
+
+func TestBindEnumNumber(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/shelves/1/books/2?c=1", nil)
+	if req := bound(t, serve(r)); req.C != Request_RED {
+		t.Errorf("c = %s, want %s", req.C, Request_RED)
+	}
+}
+
+func TestBindInvalidParams(t *testing.T) {
+	for target, field := range map[string]string{
+		"/v1/shelves/1/books/2?c=99":       "c",
+		"/v1/shelves/1/books/2?c=GREEN":    "c",
+		"/v1/shelves/1/books/2?count=x":    "count",
+		"/v1/shelves/1/books/2?time=x":     "time",
+		"/v1/shelves/1/books/2?labels[x]=": "labels",
+	} {
+		w := serve(httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", target, w.Code, http.StatusBadRequest)
+			continue
+		}
+		p := &spb.Status{}
+		if err := protojson.Unmarshal(w.Body.Bytes(), p); err != nil {
+			t.Fatal(err)
+		}
+		st := status.FromProto(p)
+		if st.Code() != codes.InvalidArgument {
+			t.Errorf("%s: code = %s, want %s", target, st.Code(), codes.InvalidArgument)
+		}
+		if details := st.Details(); len(details) != 1 {
+			t.Errorf("%s: details = %v, want a BadRequest", target, details)
+		} else if br, ok := details[0].(*errdetails.BadRequest); !ok || br.FieldViolations[0].Field != field {
+			t.Errorf("%s: details = %v, want a BadRequest of %s", target, details, field)
+		}
+	}
+}
+
+func TestBindBodyField(t *testing.T) {
+	for _, method := range []string{http.MethodPatch, http.MethodPut} {
+		r := httptest.NewRequest(method, "/v1/shelves/1/books/2?count=1", strings.NewReader(`{"title":"t"}`))
+		r.Header.Set("Content-Type", "application/json")
+		count := int32(1)
+		want := &Request{
+			Book:  &Book{Name: "shelves/1/books/2", Title: "t"},
+			Count: &count,
+		}
+		if req := bound(t, serve(r)); !proto.Equal(req, want) {
+			t.Errorf("%s: request = %v, want %v", method, req, want)
+		}
+	}
+}
+
+func TestBindWildcard(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/files/a/b/c.txt?book.name=n", nil)
+	want := &Request{
+		Book: &Book{Name: "n"},
+		Path: "a/b/c.txt",
+	}
+	if req := bound(t, serve(r)); !proto.Equal(req, want) {
+		t.Errorf("request = %v, want %v", req, want)
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	var methods []string
+	record := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		methods = append(methods, info.FullMethod)
+		return handler(ctx, req)
+	}
+	params := httprouter.Params{{Key: "path", Value: "/a"}}
+	r := httptest.NewRequest(http.MethodGet, "/files/a", nil)
+	resp, err := _Binding_GetFile_HttpHandler(testBindingServer{}, httptest.NewRecorder(), r, params, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 1 || methods[0] != "binding.Binding.GetFile" {
+		t.Errorf("methods = %v, want [binding.Binding.GetFile]", methods)
+	}
+	if _, ok := resp.(*Response); !ok {
+		t.Errorf("resp = %v, want a Response", resp)
+	}
+
+	deny := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+	r = httptest.NewRequest(http.MethodGet, "/files/a", nil)
+	if _, err := _Binding_GetFile_HttpHandler(testBindingServer{}, httptest.NewRecorder(), r, params, deny); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("err = %v, want code %s", err, codes.PermissionDenied)
+	}
+}
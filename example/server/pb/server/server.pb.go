@@ -21,6 +21,55 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Unary_Color int32
+
+const (
+	Unary_COLOR_UNSPECIFIED Unary_Color = 0
+	Unary_RED               Unary_Color = 1
+	Unary_BLUE              Unary_Color = 2
+)
+
+// Enum value maps for Unary_Color.
+var (
+	Unary_Color_name = map[int32]string{
+		0: "COLOR_UNSPECIFIED",
+		1: "RED",
+		2: "BLUE",
+	}
+	Unary_Color_value = map[string]int32{
+		"COLOR_UNSPECIFIED": 0,
+		"RED":               1,
+		"BLUE":              2,
+	}
+)
+
+func (x Unary_Color) Enum() *Unary_Color {
+	p := new(Unary_Color)
+	*p = x
+	return p
+}
+
+func (x Unary_Color) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Unary_Color) Descriptor() protoreflect.EnumDescriptor {
+	return file_server_proto_enumTypes[0].Descriptor()
+}
+
+func (Unary_Color) Type() protoreflect.EnumType {
+	return &file_server_proto_enumTypes[0]
+}
+
+func (x Unary_Color) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Unary_Color.Descriptor instead.
+func (Unary_Color) EnumDescriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{0, 0}
+}
+
 type Unary struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -165,8 +214,13 @@ type Unary_Response struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Message    string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	TestHeader int64  `protobuf:"varint,2,opt,name=test_header,json=testHeader,proto3" json:"test_header,omitempty"`
+	Message    string       `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	TestHeader int64        `protobuf:"varint,2,opt,name=test_header,json=testHeader,proto3" json:"test_header,omitempty"`
+	Color      *Unary_Color `protobuf:"varint,3,opt,name=color,proto3,enum=errors.Unary_Color,oneof" json:"color,omitempty"`
+	Digest     []byte       `protobuf:"bytes,4,opt,name=digest,proto3,oneof" json:"digest,omitempty"`
+	Session    *string      `protobuf:"bytes,5,opt,name=session,proto3,oneof" json:"session,omitempty"`
+	Token      []byte       `protobuf:"bytes,6,opt,name=token,proto3,oneof" json:"token,omitempty"`
+	Id         int32        `protobuf:"varint,7,opt,name=id,proto3" json:"id,omitempty"`
 }
 
 func (x *Unary_Response) Reset() {
@@ -215,6 +269,41 @@ func (x *Unary_Response) GetTestHeader() int64 {
 	return 0
 }
 
+func (x *Unary_Response) GetColor() Unary_Color {
+	if x != nil && x.Color != nil {
+		return *x.Color
+	}
+	return Unary_COLOR_UNSPECIFIED
+}
+
+func (x *Unary_Response) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *Unary_Response) GetSession() string {
+	if x != nil && x.Session != nil {
+		return *x.Session
+	}
+	return ""
+}
+
+func (x *Unary_Response) GetToken() []byte {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *Unary_Response) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 type Stream_Request struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -314,70 +403,93 @@ var File_server_proto protoreflect.FileDescriptor
 var file_server_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06,
 	0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x1a, 0x0d, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc2, 0x01, 0x0a, 0x05, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x1a,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x83, 0x04, 0x0a, 0x05, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x1a,
 	0x66, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x02, 0x69, 0x64,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x42, 0x0e, 0xba, 0xd5, 0xaa, 0xb1, 0x02, 0x02, 0x69, 0x64,
 	0xc8, 0xd5, 0xaa, 0xb1, 0x02, 0x01, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
 	0x73, 0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x05, 0x65, 0x78, 0x74, 0x72, 0x61, 0x18, 0x03, 0x20,
 	0x01, 0x28, 0x09, 0x42, 0x0b, 0xaa, 0xd5, 0xaa, 0xb1, 0x02, 0x05, 0x65, 0x78, 0x74, 0x72, 0x61,
-	0x52, 0x05, 0x65, 0x78, 0x74, 0x72, 0x61, 0x1a, 0x51, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2b, 0x0a,
-	0x0b, 0x74, 0x65, 0x73, 0x74, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x03, 0x42, 0x0a, 0xb2, 0xd5, 0xaa, 0xb1, 0x02, 0x04, 0x74, 0x65, 0x73, 0x74, 0x52, 0x0a,
-	0x74, 0x65, 0x73, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0x53, 0x0a, 0x06, 0x53, 0x74,
-	0x72, 0x65, 0x61, 0x6d, 0x1a, 0x23, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x24, 0x0a, 0x08, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32,
-	0xa3, 0x03, 0x0a, 0x07, 0x45, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x6c, 0x0a, 0x05, 0x55,
-	0x6e, 0x61, 0x72, 0x79, 0x12, 0x15, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x55, 0x6e,
-	0x61, 0x72, 0x79, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x73, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x34, 0x8a, 0xcf, 0xaa, 0xb1, 0x02, 0x2e, 0x0a, 0x0d, 0x75, 0x6e, 0x61,
-	0x72, 0x79, 0x20, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x65, 0x78, 0x70, 0x65,
-	0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x5a, 0x0f, 0x2f, 0x75, 0x6e, 0x61, 0x72, 0x79,
-	0x5f, 0x65, 0x63, 0x68, 0x6f, 0x2f, 0x3a, 0x69, 0x64, 0x12, 0x5e, 0x0a, 0x0e, 0x53, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x2e, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x19, 0x8a, 0xcf,
-	0xaa, 0xb1, 0x02, 0x13, 0x8a, 0x01, 0x10, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x72,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x5c, 0x0a, 0x0d, 0x53, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65,
-	0x61, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x18, 0x8a, 0xcf, 0xaa,
-	0xb1, 0x02, 0x12, 0x8a, 0x01, 0x0f, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x72, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x28, 0x01, 0x12, 0x5c, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x44, 0x75, 0x70, 0x6c, 0x65, 0x78, 0x12, 0x16, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73,
-	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x17, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x17, 0x8a, 0xcf, 0xaa, 0xb1, 0x02, 0x11,
-	0x8a, 0x01, 0x0e, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x64, 0x75, 0x70, 0x6c, 0x65,
-	0x78, 0x28, 0x01, 0x30, 0x01, 0x1a, 0x0e, 0xea, 0xc8, 0xaa, 0xb1, 0x02, 0x08, 0x2f, 0x65, 0x78,
-	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x42, 0xa9, 0x02, 0x5a, 0x0e, 0x70, 0x62, 0x2f, 0x73, 0x65, 0x72,
-	0x76, 0x65, 0x72, 0x3b, 0x70, 0x62, 0x67, 0x6f, 0xd2, 0xc2, 0xaa, 0xb1, 0x02, 0x6c, 0x0a, 0x20,
-	0x68, 0x74, 0x74, 0x70, 0x3a, 0x2f, 0x2f, 0x7b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d,
-	0x65, 0x6e, 0x74, 0x7d, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x63, 0x6f, 0x6d,
-	0x12, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x20, 0x75, 0x72, 0x6c, 0x1a, 0x3c, 0x0a, 0x0b,
-	0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x64,
-	0x65, 0x76, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x0a, 0x04, 0x70, 0x72, 0x6f,
-	0x64, 0x12, 0x03, 0x64, 0x65, 0x76, 0x1a, 0x12, 0x63, 0x68, 0x6f, 0x6f, 0x73, 0x65, 0x20, 0x65,
-	0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0xda, 0xc2, 0xaa, 0xb1, 0x02, 0x16,
-	0x0a, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x04, 0x75, 0x73, 0x65,
-	0x72, 0x0a, 0x03, 0x61, 0x6c, 0x6c, 0xe2, 0xc2, 0xaa, 0xb1, 0x02, 0x0e, 0x0a, 0x0c, 0x65, 0x78,
-	0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0xca, 0xc2, 0xaa, 0xb1, 0x02, 0x71,
-	0x0a, 0x0f, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x20, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x12, 0x1f, 0x74, 0x68, 0x69, 0x73, 0x20, 0x69, 0x73, 0x20, 0x6a, 0x75, 0x73, 0x74, 0x20,
-	0x61, 0x6e, 0x20, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x20, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x1a, 0x13, 0x74, 0x68, 0x69, 0x73, 0x20, 0x69, 0x73, 0x20, 0x64, 0x65, 0x73, 0x63,
-	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x2a, 0x1a, 0x0a, 0x05, 0x61, 0x64, 0x6d, 0x69, 0x6e,
-	0x1a, 0x11, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x40, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e,
-	0x63, 0x6f, 0x6d, 0x32, 0x05, 0x0a, 0x03, 0x4d, 0x49, 0x54, 0x3a, 0x05, 0x30, 0x2e, 0x30, 0x2e,
-	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x05, 0x65, 0x78, 0x74, 0x72, 0x61, 0x1a, 0xde, 0x02, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2b,
+	0x0a, 0x0b, 0x74, 0x65, 0x73, 0x74, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x42, 0x0a, 0xb2, 0xd5, 0xaa, 0xb1, 0x02, 0x04, 0x74, 0x65, 0x73, 0x74, 0x52,
+	0x0a, 0x74, 0x65, 0x73, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x05, 0x63,
+	0x6f, 0x6c, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x73, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x42,
+	0x0d, 0xb2, 0xd5, 0xaa, 0xb1, 0x02, 0x07, 0x58, 0x2d, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x48, 0x00,
+	0x52, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x06, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x42, 0x0e, 0xb2, 0xd5, 0xaa, 0xb1,
+	0x02, 0x08, 0x58, 0x2d, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x48, 0x01, 0x52, 0x06, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x88, 0x01, 0x01, 0x12, 0x3c, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x42, 0x1d, 0xc2, 0xd5, 0xaa, 0xb1, 0x02, 0x07,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0xea, 0xd5, 0xaa, 0xb1, 0x02, 0x0a, 0x0a, 0x01, 0x2f,
+	0x28, 0x01, 0x32, 0x03, 0x6c, 0x61, 0x78, 0x48, 0x02, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0c, 0x42, 0x0b, 0xc2, 0xd5, 0xaa, 0xb1, 0x02, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x48, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x42, 0x08, 0x0a,
+	0x06, 0x5f, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x08,
+	0x0a, 0x06, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x31, 0x0a, 0x05, 0x43, 0x6f, 0x6c, 0x6f,
+	0x72, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f, 0x4c, 0x4f, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x52, 0x45, 0x44, 0x10,
+	0x01, 0x12, 0x08, 0x0a, 0x04, 0x42, 0x4c, 0x55, 0x45, 0x10, 0x02, 0x22, 0x53, 0x0a, 0x06, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x1a, 0x23, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x24, 0x0a, 0x08, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x32, 0xd7, 0x03, 0x0a, 0x07, 0x45, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x9f, 0x01, 0x0a,
+	0x05, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x12, 0x15, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e,
+	0x55, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x67, 0x8a, 0xcf, 0xaa, 0xb1, 0x02, 0x61, 0x0a, 0x0d, 0x75,
+	0x6e, 0x61, 0x72, 0x79, 0x20, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x65, 0x78,
+	0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x5a, 0x0f, 0x2f, 0x75, 0x6e, 0x61,
+	0x72, 0x79, 0x5f, 0x65, 0x63, 0x68, 0x6f, 0x2f, 0x3a, 0x69, 0x64, 0x9a, 0x01, 0x30, 0x52, 0x0f,
+	0x2f, 0x75, 0x6e, 0x61, 0x72, 0x79, 0x5f, 0x65, 0x63, 0x68, 0x6f, 0x2f, 0x3a, 0x69, 0x64, 0xa8,
+	0x01, 0xc9, 0x01, 0xb2, 0x01, 0x18, 0x2f, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2f, 0x75,
+	0x6e, 0x61, 0x72, 0x79, 0x5f, 0x65, 0x63, 0x68, 0x6f, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12, 0x5e,
+	0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x16, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x19, 0x8a, 0xcf, 0xaa, 0xb1, 0x02, 0x13, 0x8a, 0x01, 0x10, 0x2f, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x5c,
+	0x0a, 0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x16, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x18, 0x8a, 0xcf, 0xaa, 0xb1, 0x02, 0x12, 0x8a, 0x01, 0x0f, 0x2f, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x28, 0x01, 0x12, 0x5c, 0x0a, 0x0c,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x75, 0x70, 0x6c, 0x65, 0x78, 0x12, 0x16, 0x2e, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x17, 0x8a,
+	0xcf, 0xaa, 0xb1, 0x02, 0x11, 0x8a, 0x01, 0x0e, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f,
+	0x64, 0x75, 0x70, 0x6c, 0x65, 0x78, 0x28, 0x01, 0x30, 0x01, 0x1a, 0x0e, 0xea, 0xc8, 0xaa, 0xb1,
+	0x02, 0x08, 0x2f, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x42, 0xa9, 0x02, 0x5a, 0x0e, 0x70,
+	0x62, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x3b, 0x70, 0x62, 0x67, 0x6f, 0xca, 0xc2, 0xaa,
+	0xb1, 0x02, 0x71, 0x0a, 0x0f, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x20, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x1f, 0x74, 0x68, 0x69, 0x73, 0x20, 0x69, 0x73, 0x20, 0x6a, 0x75,
+	0x73, 0x74, 0x20, 0x61, 0x6e, 0x20, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x20, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x1a, 0x13, 0x74, 0x68, 0x69, 0x73, 0x20, 0x69, 0x73, 0x20, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x2a, 0x1a, 0x0a, 0x05, 0x61, 0x64,
+	0x6d, 0x69, 0x6e, 0x1a, 0x11, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x40, 0x65, 0x78, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x32, 0x05, 0x0a, 0x03, 0x4d, 0x49, 0x54, 0x3a, 0x05, 0x30,
+	0x2e, 0x30, 0x2e, 0x31, 0xd2, 0xc2, 0xaa, 0xb1, 0x02, 0x6c, 0x0a, 0x20, 0x68, 0x74, 0x74, 0x70,
+	0x3a, 0x2f, 0x2f, 0x7b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x7d,
+	0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x12, 0x0a, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x20, 0x75, 0x72, 0x6c, 0x1a, 0x3c, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69,
+	0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x03, 0x64, 0x65, 0x76, 0x0a, 0x07,
+	0x73, 0x74, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x0a, 0x04, 0x70, 0x72, 0x6f, 0x64, 0x12, 0x03, 0x64,
+	0x65, 0x76, 0x1a, 0x12, 0x63, 0x68, 0x6f, 0x6f, 0x73, 0x65, 0x20, 0x65, 0x6e, 0x76, 0x69, 0x72,
+	0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0xda, 0xc2, 0xaa, 0xb1, 0x02, 0x16, 0x0a, 0x14, 0x0a, 0x05,
+	0x73, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x0a, 0x03, 0x61,
+	0x6c, 0x6c, 0xe2, 0xc2, 0xaa, 0xb1, 0x02, 0x0e, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69,
+	0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -392,29 +504,32 @@ func file_server_proto_rawDescGZIP() []byte {
 	return file_server_proto_rawDescData
 }
 
+var file_server_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_server_proto_goTypes = []interface{}{
-	(*Unary)(nil),           // 0: errors.Unary
-	(*Stream)(nil),          // 1: errors.Stream
-	(*Unary_Request)(nil),   // 2: errors.Unary.Request
-	(*Unary_Response)(nil),  // 3: errors.Unary.Response
-	(*Stream_Request)(nil),  // 4: errors.Stream.Request
-	(*Stream_Response)(nil), // 5: errors.Stream.Response
+	(Unary_Color)(0),        // 0: errors.Unary.Color
+	(*Unary)(nil),           // 1: errors.Unary
+	(*Stream)(nil),          // 2: errors.Stream
+	(*Unary_Request)(nil),   // 3: errors.Unary.Request
+	(*Unary_Response)(nil),  // 4: errors.Unary.Response
+	(*Stream_Request)(nil),  // 5: errors.Stream.Request
+	(*Stream_Response)(nil), // 6: errors.Stream.Response
 }
 var file_server_proto_depIdxs = []int32{
-	2, // 0: errors.Example.Unary:input_type -> errors.Unary.Request
-	4, // 1: errors.Example.StreamResponse:input_type -> errors.Stream.Request
-	4, // 2: errors.Example.StreamRequest:input_type -> errors.Stream.Request
-	4, // 3: errors.Example.StreamDuplex:input_type -> errors.Stream.Request
-	3, // 4: errors.Example.Unary:output_type -> errors.Unary.Response
-	5, // 5: errors.Example.StreamResponse:output_type -> errors.Stream.Response
-	5, // 6: errors.Example.StreamRequest:output_type -> errors.Stream.Response
-	5, // 7: errors.Example.StreamDuplex:output_type -> errors.Stream.Response
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: errors.Unary.Response.color:type_name -> errors.Unary.Color
+	3, // 1: errors.Example.Unary:input_type -> errors.Unary.Request
+	5, // 2: errors.Example.StreamResponse:input_type -> errors.Stream.Request
+	5, // 3: errors.Example.StreamRequest:input_type -> errors.Stream.Request
+	5, // 4: errors.Example.StreamDuplex:input_type -> errors.Stream.Request
+	4, // 5: errors.Example.Unary:output_type -> errors.Unary.Response
+	6, // 6: errors.Example.StreamResponse:output_type -> errors.Stream.Response
+	6, // 7: errors.Example.StreamRequest:output_type -> errors.Stream.Response
+	6, // 8: errors.Example.StreamDuplex:output_type -> errors.Stream.Response
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_init() }
@@ -496,18 +611,20 @@ func file_server_proto_init() {
 			}
 		}
 	}
+	file_server_proto_msgTypes[3].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_server_proto_rawDesc,
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_server_proto_goTypes,
 		DependencyIndexes: file_server_proto_depIdxs,
+		EnumInfos:         file_server_proto_enumTypes,
 		MessageInfos:      file_server_proto_msgTypes,
 	}.Build()
 	File_server_proto = out.File
@@ -3,7 +3,7 @@ package pbgo
 
 import (
 	context "context"
-	fmt "fmt"
+	base64 "encoding/base64"
 	protoweb "github.com/joesonw/proto-web/pkg/protoweb"
 	httprouter "github.com/julienschmidt/httprouter"
 	grpc "google.golang.org/grpc"
@@ -25,17 +25,99 @@ func _Example_Unary_HttpHandler(srv interface{}, w http.ResponseWriter, r *http.
 	if err != nil {
 		return nil, err
 	}
-	if err := (protojson.UnmarshalOptions{}).Unmarshal(b, req); err != nil {
+	if len(b) > 0 {
+		if err := (protojson.UnmarshalOptions{}).Unmarshal(b, req); err != nil {
+			return nil, err
+		}
+	}
+	var errs protoweb.ParamErrors
+	x1, err := strconv.ParseInt(params.ByName("id"), 10, 32)
+	req.Id = int32(x1)
+	if err != nil {
+		errs.Add("id", "path", "id", err)
+	}
+	if _, ok := r.URL.Query()["extra"]; ok {
+		req.Extra = r.URL.Query().Get("extra")
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	var res *Unary_Response
+	if interceptor == nil {
+		res, err = srv.(ExampleServer).Unary(ctx, req)
+	} else {
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: "errors.Example.Unary",
+		}
+
+		handler := func(ctx context.Context, in interface{}) (interface{}, error) {
+			return srv.(ExampleServer).Unary(ctx, in.(*Unary_Request))
+		}
+		var resp interface{}
+		resp, err = interceptor(ctx, req, info, handler)
+		res, _ = resp.(*Unary_Response)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.Header().Set("test", strconv.FormatInt(res.TestHeader, 10))
+	res.TestHeader = 0
+	if res.Color != nil {
+		w.Header().Set("X-Color", (*res.Color).String())
+	}
+	res.Color = nil
+	if res.Digest != nil {
+		w.Header().Set("X-Digest", base64.StdEncoding.EncodeToString(res.Digest))
+	}
+	res.Digest = nil
+	if res.Session != nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    (*res.Session),
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	res.Session = nil
+	if res.Token != nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:  "token",
+			Value: base64.StdEncoding.EncodeToString(res.Token),
+		})
+	}
+	res.Token = nil
+	return res, nil
+}
+func _Example_Unary_HttpHandler1(srv interface{}, w http.ResponseWriter, r *http.Request, params httprouter.Params, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	var err error
+	ctx := r.Context()
+	req := &Unary_Request{}
+	b, err := ioutil.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
 		return nil, err
 	}
-	x1, err := strconv.ParseInt(params.ByName("id"), 10, 64)
+	if len(b) > 0 {
+		if err := (protojson.UnmarshalOptions{}).Unmarshal(b, req); err != nil {
+			return nil, err
+		}
+	}
+	var errs protoweb.ParamErrors
+	x1, err := strconv.ParseInt(params.ByName("id"), 10, 32)
 	req.Id = int32(x1)
 	if err != nil {
+		errs.Add("id", "path", "id", err)
+	}
+	if _, ok := r.URL.Query()["extra"]; ok {
+		req.Extra = r.URL.Query().Get("extra")
+	}
+	if err := errs.Err(); err != nil {
 		return nil, err
 	}
-	req.Extra = r.URL.Query().Get("extra")
 	var res *Unary_Response
-	if interceptor != nil {
+	if interceptor == nil {
 		res, err = srv.(ExampleServer).Unary(ctx, req)
 	} else {
 		info := &grpc.UnaryServerInfo{
@@ -48,12 +130,51 @@ func _Example_Unary_HttpHandler(srv interface{}, w http.ResponseWriter, r *http.
 		}
 		var resp interface{}
 		resp, err = interceptor(ctx, req, info, handler)
-		res = resp.(*Unary_Response)
+		res, _ = resp.(*Unary_Response)
 	}
-	w.Header().Set("test", fmt.Sprintf("%v", res.TestHeader))
+	if err != nil {
+		return nil, err
+	}
+	w.Header().Set("test", strconv.FormatInt(res.TestHeader, 10))
 	res.TestHeader = 0
+	if res.Color != nil {
+		w.Header().Set("X-Color", (*res.Color).String())
+	}
+	res.Color = nil
+	if res.Digest != nil {
+		w.Header().Set("X-Digest", base64.StdEncoding.EncodeToString(res.Digest))
+	}
+	res.Digest = nil
+	if res.Session != nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    (*res.Session),
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	res.Session = nil
+	if res.Token != nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:  "token",
+			Value: base64.StdEncoding.EncodeToString(res.Token),
+		})
+	}
+	res.Token = nil
 	return res, nil
 }
+func _Example_StreamResponse_HttpBinder(m interface{}, r *http.Request, params httprouter.Params) (err error) {
+	req := m.(*Stream_Request)
+	var errs protoweb.ParamErrors
+	if _, ok := r.URL.Query()["message"]; ok {
+		req.Message = r.URL.Query().Get("message")
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	return nil
+}
 
 var Example_HttpServiceDesc = protoweb.ServiceDesc{
 	ServiceName: "errors.Example",
@@ -65,6 +186,14 @@ var Example_HttpServiceDesc = protoweb.ServiceDesc{
 			HttpMethod: "POST",
 			Handler:    _Example_Unary_HttpHandler,
 		},
+		{
+			MethodName: "Unary",
+			Path:       "/unary_echo/:id",
+			HttpMethod: "PUT",
+			Status:     201,
+			Location:   "/example/unary_echo/{id}",
+			Handler:    _Example_Unary_HttpHandler1,
+		},
 	},
 	Streams: []protoweb.StreamDesc{
 		{
@@ -72,6 +201,7 @@ var Example_HttpServiceDesc = protoweb.ServiceDesc{
 			Path:          "/stream_response",
 			Handler:       _Example_StreamResponse_Handler,
 			ServerStreams: true,
+			Binder:        _Example_StreamResponse_HttpBinder,
 		},
 		{
 			StreamName:    "StreamRequest",
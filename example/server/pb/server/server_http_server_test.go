@@ -0,0 +1,70 @@
+package pbgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/joesonw/proto-web/pkg/protoweb"
+)
+
+type testExampleServer struct {
+	UnimplementedExampleServer
+}
+
+func (testExampleServer) Unary(ctx context.Context, req *Unary_Request) (*Unary_Response, error) {
+	return &Unary_Response{Id: req.Id, Message: req.Message}, nil
+}
+
+func newUnaryRequest() (*http.Request, httprouter.Params) {
+	r := httptest.NewRequest(http.MethodPost, "/unary_echo/1", strings.NewReader(`{"message":"hi"}`))
+	r.Header.Set("Content-Type", "application/json")
+	return r, httprouter.Params{{Key: "id", Value: "1"}}
+}
+
+func TestUnaryWithoutInterceptor(t *testing.T) {
+	r, params := newUnaryRequest()
+	resp, err := _Example_Unary_HttpHandler(testExampleServer{}, httptest.NewRecorder(), r, params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := resp.(*Unary_Response); res.Message != "hi" {
+		t.Errorf("message = %q, want hi", res.Message)
+	}
+}
+
+func TestUnaryInterceptorDenied(t *testing.T) {
+	deny := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+	r, params := newUnaryRequest()
+	resp, err := _Example_Unary_HttpHandler(testExampleServer{}, httptest.NewRecorder(), r, params, deny)
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("err = %v, want code %s", err, codes.PermissionDenied)
+	}
+	if resp != nil {
+		t.Errorf("resp = %v, want nil", resp)
+	}
+}
+
+func TestUnaryCreatedLocation(t *testing.T) {
+	s := protoweb.NewServer()
+	RegisterExampleHTTPServer(s, testExampleServer{})
+	r := httptest.NewRequest(http.MethodPut, "/unary_echo/7", strings.NewReader(`{"message":"hi"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if loc := w.Header().Get("Location"); loc != "/example/unary_echo/7" {
+		t.Errorf("location = %q, want /example/unary_echo/7", loc)
+	}
+}
@@ -5,12 +5,14 @@ go 1.16
 require (
 	github.com/go-playground/universal-translator v0.18.0 // indirect
 	github.com/go-playground/validator/v10 v10.9.0 // indirect
+	github.com/gobwas/httphead v0.1.0
 	github.com/gobwas/ws v1.1.0 // indirect
 	github.com/iancoleman/strcase v0.2.0 // indirect
 	github.com/joesonw/proto-tools v0.1.3
 	github.com/julienschmidt/httprouter v1.3.0 // indirect
 	github.com/leodido/go-urn v1.2.1 // indirect
 	go.uber.org/zap v1.19.0 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.40.0 // indirect
 	google.golang.org/protobuf v1.25.0
 )
@@ -438,22 +438,27 @@ type Path struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Summary      string                 `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
-	Tags         []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
-	ExternalDocs *ExternalDocumentation `protobuf:"bytes,4,opt,name=external_docs,json=externalDocs,proto3" json:"external_docs,omitempty"`
-	Id           string                 `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
-	Deprecated   bool                   `protobuf:"varint,6,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
-	Security     []*SecurityRequirement `protobuf:"bytes,7,rep,name=security,proto3" json:"security,omitempty"`
-	Servers      []*Server              `protobuf:"bytes,8,rep,name=servers,proto3" json:"servers,omitempty"`
-	Get          string                 `protobuf:"bytes,9,opt,name=get,proto3" json:"get,omitempty"`
-	Put          string                 `protobuf:"bytes,10,opt,name=put,proto3" json:"put,omitempty"`
-	Post         string                 `protobuf:"bytes,11,opt,name=post,proto3" json:"post,omitempty"`
-	Delete       string                 `protobuf:"bytes,12,opt,name=delete,proto3" json:"delete,omitempty"`
-	Options      string                 `protobuf:"bytes,13,opt,name=options,proto3" json:"options,omitempty"`
-	Head         string                 `protobuf:"bytes,14,opt,name=head,proto3" json:"head,omitempty"`
-	Patch        string                 `protobuf:"bytes,15,opt,name=patch,proto3" json:"patch,omitempty"`
-	Trace        string                 `protobuf:"bytes,16,opt,name=trace,proto3" json:"trace,omitempty"`
-	Stream       string                 `protobuf:"bytes,17,opt,name=stream,proto3" json:"stream,omitempty"`
+	Summary            string                 `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
+	Tags               []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	ExternalDocs       *ExternalDocumentation `protobuf:"bytes,4,opt,name=external_docs,json=externalDocs,proto3" json:"external_docs,omitempty"`
+	Id                 string                 `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	Deprecated         bool                   `protobuf:"varint,6,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	Security           []*SecurityRequirement `protobuf:"bytes,7,rep,name=security,proto3" json:"security,omitempty"`
+	Servers            []*Server              `protobuf:"bytes,8,rep,name=servers,proto3" json:"servers,omitempty"`
+	Get                string                 `protobuf:"bytes,9,opt,name=get,proto3" json:"get,omitempty"`
+	Put                string                 `protobuf:"bytes,10,opt,name=put,proto3" json:"put,omitempty"`
+	Post               string                 `protobuf:"bytes,11,opt,name=post,proto3" json:"post,omitempty"`
+	Delete             string                 `protobuf:"bytes,12,opt,name=delete,proto3" json:"delete,omitempty"`
+	Options            string                 `protobuf:"bytes,13,opt,name=options,proto3" json:"options,omitempty"`
+	Head               string                 `protobuf:"bytes,14,opt,name=head,proto3" json:"head,omitempty"`
+	Patch              string                 `protobuf:"bytes,15,opt,name=patch,proto3" json:"patch,omitempty"`
+	Trace              string                 `protobuf:"bytes,16,opt,name=trace,proto3" json:"trace,omitempty"`
+	Stream             string                 `protobuf:"bytes,17,opt,name=stream,proto3" json:"stream,omitempty"`
+	Download           bool                   `protobuf:"varint,18,opt,name=download,proto3" json:"download,omitempty"`
+	AdditionalBindings []*Path                `protobuf:"bytes,19,rep,name=additional_bindings,json=additionalBindings,proto3" json:"additional_bindings,omitempty"`
+	Body               string                 `protobuf:"bytes,20,opt,name=body,proto3" json:"body,omitempty"`
+	Status             int32                  `protobuf:"varint,21,opt,name=status,proto3" json:"status,omitempty"`
+	Location           string                 `protobuf:"bytes,22,opt,name=location,proto3" json:"location,omitempty"`
 }
 
 func (x *Path) Reset() {
@@ -600,6 +605,41 @@ func (x *Path) GetStream() string {
 	return ""
 }
 
+func (x *Path) GetDownload() bool {
+	if x != nil {
+		return x.Download
+	}
+	return false
+}
+
+func (x *Path) GetAdditionalBindings() []*Path {
+	if x != nil {
+		return x.AdditionalBindings
+	}
+	return nil
+}
+
+func (x *Path) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Path) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Path) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
 type ExternalDocumentation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -702,6 +742,93 @@ func (x *SecurityRequirement) GetScopes() map[string]*SecurityRequirement_Scope
 	return nil
 }
 
+type Cookie struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path     string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Domain   string `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	MaxAge   int32  `protobuf:"varint,3,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`
+	Secure   bool   `protobuf:"varint,4,opt,name=secure,proto3" json:"secure,omitempty"`
+	HttpOnly bool   `protobuf:"varint,5,opt,name=http_only,json=httpOnly,proto3" json:"http_only,omitempty"`
+	SameSite string `protobuf:"bytes,6,opt,name=same_site,json=sameSite,proto3" json:"same_site,omitempty"`
+}
+
+func (x *Cookie) Reset() {
+	*x = Cookie{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_openapi_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Cookie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cookie) ProtoMessage() {}
+
+func (x *Cookie) ProtoReflect() protoreflect.Message {
+	mi := &file_openapi_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cookie.ProtoReflect.Descriptor instead.
+func (*Cookie) Descriptor() ([]byte, []int) {
+	return file_openapi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Cookie) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Cookie) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Cookie) GetMaxAge() int32 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+func (x *Cookie) GetSecure() bool {
+	if x != nil {
+		return x.Secure
+	}
+	return false
+}
+
+func (x *Cookie) GetHttpOnly() bool {
+	if x != nil {
+		return x.HttpOnly
+	}
+	return false
+}
+
+func (x *Cookie) GetSameSite() string {
+	if x != nil {
+		return x.SameSite
+	}
+	return ""
+}
+
 type SecurityRequirement_Scope struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -713,7 +840,7 @@ type SecurityRequirement_Scope struct {
 func (x *SecurityRequirement_Scope) Reset() {
 	*x = SecurityRequirement_Scope{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_openapi_proto_msgTypes[10]
+		mi := &file_openapi_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -726,7 +853,7 @@ func (x *SecurityRequirement_Scope) String() string {
 func (*SecurityRequirement_Scope) ProtoMessage() {}
 
 func (x *SecurityRequirement_Scope) ProtoReflect() protoreflect.Message {
-	mi := &file_openapi_proto_msgTypes[10]
+	mi := &file_openapi_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -862,6 +989,22 @@ var file_openapi_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "varint,80041307,opt,name=allow_empty_value",
 		Filename:      "openapi.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         80041308,
+		Name:          "com.github.joesonw.proto_web.openapi.style",
+		Tag:           "bytes,80041308,opt,name=style",
+		Filename:      "openapi.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*Cookie)(nil),
+		Field:         80041309,
+		Name:          "com.github.joesonw.proto_web.openapi.cookie",
+		Tag:           "bytes,80041309,opt,name=cookie",
+		Filename:      "openapi.proto",
+	},
 }
 
 // Extension fields to descriptorpb.FileOptions.
@@ -906,6 +1049,10 @@ var (
 	E_Deprecated = &file_openapi_proto_extTypes[12]
 	// optional bool allow_empty_value = 80041307;
 	E_AllowEmptyValue = &file_openapi_proto_extTypes[13]
+	// optional string style = 80041308;
+	E_Style = &file_openapi_proto_extTypes[14]
+	// optional com.github.joesonw.proto_web.openapi.Cookie cookie = 80041309;
+	E_Cookie = &file_openapi_proto_extTypes[15]
 )
 
 var File_openapi_proto protoreflect.FileDescriptor
@@ -986,7 +1133,7 @@ var file_openapi_proto_rawDesc = []byte{
 	0xab, 0xb1, 0x02, 0x01, 0x52, 0x07, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x20, 0x0a,
 	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x3a,
-	0x06, 0xc8, 0xbf, 0xab, 0xb1, 0x02, 0x01, 0x22, 0xa7, 0x04, 0x0a, 0x04, 0x50, 0x61, 0x74, 0x68,
+	0x06, 0xc8, 0xbf, 0xab, 0xb1, 0x02, 0x01, 0x22, 0xe8, 0x05, 0x0a, 0x04, 0x50, 0x61, 0x74, 0x68,
 	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61,
 	0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x60,
@@ -1021,106 +1168,138 @@ var file_openapi_proto_rawDesc = []byte{
 	0x74, 0x63, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x18, 0x10, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x05, 0x74, 0x72, 0x61, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72,
 	0x65, 0x61, 0x6d, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x22, 0x53, 0x0a, 0x15, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x44, 0x6f, 0x63,
-	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65,
-	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03,
-	0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x3a, 0x06,
-	0xc8, 0xbf, 0xab, 0xb1, 0x02, 0x01, 0x22, 0x99, 0x02, 0x0a, 0x13, 0x53, 0x65, 0x63, 0x75, 0x72,
-	0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x5d,
-	0x0a, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x45,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73,
-	0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70,
-	0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x52, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x73,
-	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x1a, 0x1f, 0x0a,
-	0x05, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x1a, 0x7a,
-	0x0a, 0x0b, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
-	0x55, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3f,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73,
-	0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70,
-	0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x52, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x3a, 0x06, 0xc8, 0xbf, 0xab, 0xb1,
-	0x02, 0x01, 0x3a, 0x5f, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c,
-	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xa9, 0xa8, 0x95, 0x26, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a,
-	0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62,
-	0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69,
-	0x6e, 0x66, 0x6f, 0x3a, 0x67, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x1c,
-	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xaa, 0xa8, 0x95,
-	0x26, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x72,
-	0x76, 0x65, 0x72, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x3a, 0x76, 0x0a, 0x08,
-	0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f,
-	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xab, 0xa8, 0x95, 0x26, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x39, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65,
+	0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x5b, 0x0a,
+	0x13, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f, 0x62, 0x69, 0x6e, 0x64,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x13, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70,
+	0x69, 0x2e, 0x50, 0x61, 0x74, 0x68, 0x52, 0x12, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x61, 0x6c, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f,
+	0x64, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0x53, 0x0a, 0x15, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x44, 0x6f,
+	0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a,
+	0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x3a,
+	0x06, 0xc8, 0xbf, 0xab, 0xb1, 0x02, 0x01, 0x22, 0x99, 0x02, 0x0a, 0x13, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x5d, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x45, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65,
 	0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f,
 	0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x52,
-	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x73, 0x65, 0x63, 0x75,
-	0x72, 0x69, 0x74, 0x79, 0x3a, 0x5e, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1c, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46,
-	0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xac, 0xa8, 0x95, 0x26, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77,
-	0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x54, 0x61, 0x67, 0x52, 0x04,
-	0x74, 0x61, 0x67, 0x73, 0x3a, 0x81, 0x01, 0x0a, 0x0d, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61,
-	0x6c, 0x5f, 0x64, 0x6f, 0x63, 0x73, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x18, 0xad, 0xa8, 0x95, 0x26, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x3b, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f,
-	0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65,
-	0x6e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x44, 0x6f, 0x63,
-	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x65, 0x78, 0x74, 0x65,
-	0x72, 0x6e, 0x61, 0x6c, 0x44, 0x6f, 0x63, 0x73, 0x3a, 0x3a, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66,
-	0x69, 0x78, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4f, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x18, 0x8d, 0xa9, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72,
-	0x65, 0x66, 0x69, 0x78, 0x3a, 0x61, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1e, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d,
-	0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xf1, 0xa9, 0x95,
-	0x26, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x61, 0x74,
-	0x68, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x3a, 0x3b, 0x0a, 0x08, 0x69, 0x6e, 0x5f, 0x71, 0x75,
-	0x65, 0x72, 0x79, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x18, 0xd5, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6e, 0x51,
-	0x75, 0x65, 0x72, 0x79, 0x3a, 0x3d, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x70, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x1a, 0x1f,
+	0x0a, 0x05, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x1a,
+	0x7a, 0x0a, 0x0b, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x55, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x3f, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65,
+	0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f,
+	0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x70, 0x65,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x3a, 0x06, 0xc8, 0xbf, 0xab,
+	0xb1, 0x02, 0x01, 0x22, 0x9f, 0x01, 0x0a, 0x06, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61,
+	0x78, 0x5f, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6d, 0x61, 0x78,
+	0x41, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x68,
+	0x74, 0x74, 0x70, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x68, 0x74, 0x74, 0x70, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x61, 0x6d, 0x65,
+	0x5f, 0x73, 0x69, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x61, 0x6d,
+	0x65, 0x53, 0x69, 0x74, 0x65, 0x3a, 0x5f, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xa9, 0xa8, 0x95, 0x26,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f,
+	0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x3a, 0x67, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x73, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xaa, 0xa8, 0x95, 0x26, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x3a,
+	0x76, 0x0a, 0x08, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xab, 0xa8, 0x95, 0x26, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x39, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65,
+	0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69,
+	0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x73,
+	0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x3a, 0x5e, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12,
+	0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xac, 0xa8,
+	0x95, 0x26, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x54, 0x61,
+	0x67, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x3a, 0x81, 0x01, 0x0a, 0x0d, 0x65, 0x78, 0x74, 0x65,
+	0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x64, 0x6f, 0x63, 0x73, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xad, 0xa8, 0x95, 0x26, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x3b, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f,
+	0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e,
+	0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
+	0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x65,
+	0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x44, 0x6f, 0x63, 0x73, 0x3a, 0x3a, 0x0a, 0x06, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x8d, 0xa9, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x3a, 0x61, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
+	0x1e, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xf1, 0xa9, 0x95, 0x26, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e,
+	0x50, 0x61, 0x74, 0x68, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x3a, 0x3b, 0x0a, 0x08, 0x69, 0x6e,
+	0x5f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd5, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x69, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x3a, 0x3d, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0xd6, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x3a, 0x39, 0x0a, 0x07, 0x69, 0x6e, 0x5f, 0x70, 0x61, 0x74,
+	0x68, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x18, 0xd6, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x48, 0x65, 0x61,
-	0x64, 0x65, 0x72, 0x3a, 0x39, 0x0a, 0x07, 0x69, 0x6e, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1d,
+	0x18, 0xd7, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x6e, 0x50, 0x61, 0x74,
+	0x68, 0x3a, 0x3d, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x12, 0x1d,
 	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd7, 0xaa,
-	0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x6e, 0x50, 0x61, 0x74, 0x68, 0x3a, 0x3d,
-	0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69,
-	0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd8, 0xaa, 0x95, 0x26, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x3a, 0x3c, 0x0a,
-	0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c,
-	0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd9, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x3a, 0x40, 0x0a, 0x0a, 0x64,
-	0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c,
-	0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xda, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x3a, 0x4c, 0x0a,
-	0x11, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0xdb, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x61, 0x6c, 0x6c, 0x6f,
-	0x77, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x36, 0x5a, 0x34, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e,
-	0x77, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2d, 0x77, 0x65, 0x62, 0x2f, 0x70, 0x62, 0x67, 0x6f,
-	0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x3b, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69,
-	0x5f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd8, 0xaa,
+	0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65,
+	0x3a, 0x3c, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x1d, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd9, 0xaa, 0x95, 0x26,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x3a, 0x40,
+	0x0a, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xda, 0xaa, 0x95, 0x26,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64,
+	0x3a, 0x4c, 0x0a, 0x11, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0xdb, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x36,
+	0x0a, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xdc, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x3a, 0x66, 0x0a, 0x06, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65,
+	0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0xdd, 0xaa, 0x95, 0x26, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x6a, 0x6f, 0x65, 0x73, 0x6f, 0x6e, 0x77, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x5f, 0x77, 0x65, 0x62, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x52, 0x06, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x42, 0x36,
+	0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x6f, 0x65,
+	0x73, 0x6f, 0x6e, 0x77, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2d, 0x77, 0x65, 0x62, 0x2f, 0x70,
+	0x62, 0x67, 0x6f, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x3b, 0x6f, 0x70, 0x65, 0x6e,
+	0x61, 0x70, 0x69, 0x5f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1135,7 +1314,7 @@ func file_openapi_proto_rawDescGZIP() []byte {
 	return file_openapi_proto_rawDescData
 }
 
-var file_openapi_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_openapi_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_openapi_proto_goTypes = []interface{}{
 	(*Tag)(nil),                         // 0: com.github.joesonw.proto_web.openapi.Tag
 	(*Info)(nil),                        // 1: com.github.joesonw.proto_web.openapi.Info
@@ -1146,50 +1325,55 @@ var file_openapi_proto_goTypes = []interface{}{
 	(*Path)(nil),                        // 6: com.github.joesonw.proto_web.openapi.Path
 	(*ExternalDocumentation)(nil),       // 7: com.github.joesonw.proto_web.openapi.ExternalDocumentation
 	(*SecurityRequirement)(nil),         // 8: com.github.joesonw.proto_web.openapi.SecurityRequirement
-	nil,                                 // 9: com.github.joesonw.proto_web.openapi.Server.VariablesEntry
-	(*SecurityRequirement_Scope)(nil),   // 10: com.github.joesonw.proto_web.openapi.SecurityRequirement.Scope
-	nil,                                 // 11: com.github.joesonw.proto_web.openapi.SecurityRequirement.ScopesEntry
-	(*descriptorpb.FileOptions)(nil),    // 12: google.protobuf.FileOptions
-	(*descriptorpb.ServiceOptions)(nil), // 13: google.protobuf.ServiceOptions
-	(*descriptorpb.MethodOptions)(nil),  // 14: google.protobuf.MethodOptions
-	(*descriptorpb.FieldOptions)(nil),   // 15: google.protobuf.FieldOptions
+	(*Cookie)(nil),                      // 9: com.github.joesonw.proto_web.openapi.Cookie
+	nil,                                 // 10: com.github.joesonw.proto_web.openapi.Server.VariablesEntry
+	(*SecurityRequirement_Scope)(nil),   // 11: com.github.joesonw.proto_web.openapi.SecurityRequirement.Scope
+	nil,                                 // 12: com.github.joesonw.proto_web.openapi.SecurityRequirement.ScopesEntry
+	(*descriptorpb.FileOptions)(nil),    // 13: google.protobuf.FileOptions
+	(*descriptorpb.ServiceOptions)(nil), // 14: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 15: google.protobuf.MethodOptions
+	(*descriptorpb.FieldOptions)(nil),   // 16: google.protobuf.FieldOptions
 }
 var file_openapi_proto_depIdxs = []int32{
 	7,  // 0: com.github.joesonw.proto_web.openapi.Tag.external_docs:type_name -> com.github.joesonw.proto_web.openapi.ExternalDocumentation
 	2,  // 1: com.github.joesonw.proto_web.openapi.Info.contact:type_name -> com.github.joesonw.proto_web.openapi.Contact
 	3,  // 2: com.github.joesonw.proto_web.openapi.Info.license:type_name -> com.github.joesonw.proto_web.openapi.License
-	9,  // 3: com.github.joesonw.proto_web.openapi.Server.variables:type_name -> com.github.joesonw.proto_web.openapi.Server.VariablesEntry
+	10, // 3: com.github.joesonw.proto_web.openapi.Server.variables:type_name -> com.github.joesonw.proto_web.openapi.Server.VariablesEntry
 	7,  // 4: com.github.joesonw.proto_web.openapi.Path.external_docs:type_name -> com.github.joesonw.proto_web.openapi.ExternalDocumentation
 	8,  // 5: com.github.joesonw.proto_web.openapi.Path.security:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement
 	4,  // 6: com.github.joesonw.proto_web.openapi.Path.servers:type_name -> com.github.joesonw.proto_web.openapi.Server
-	11, // 7: com.github.joesonw.proto_web.openapi.SecurityRequirement.scopes:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement.ScopesEntry
-	5,  // 8: com.github.joesonw.proto_web.openapi.Server.VariablesEntry.value:type_name -> com.github.joesonw.proto_web.openapi.ServerVariable
-	10, // 9: com.github.joesonw.proto_web.openapi.SecurityRequirement.ScopesEntry.value:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement.Scope
-	12, // 10: com.github.joesonw.proto_web.openapi.info:extendee -> google.protobuf.FileOptions
-	12, // 11: com.github.joesonw.proto_web.openapi.servers:extendee -> google.protobuf.FileOptions
-	12, // 12: com.github.joesonw.proto_web.openapi.security:extendee -> google.protobuf.FileOptions
-	12, // 13: com.github.joesonw.proto_web.openapi.tags:extendee -> google.protobuf.FileOptions
-	12, // 14: com.github.joesonw.proto_web.openapi.external_docs:extendee -> google.protobuf.FileOptions
-	13, // 15: com.github.joesonw.proto_web.openapi.prefix:extendee -> google.protobuf.ServiceOptions
-	14, // 16: com.github.joesonw.proto_web.openapi.path:extendee -> google.protobuf.MethodOptions
-	15, // 17: com.github.joesonw.proto_web.openapi.in_query:extendee -> google.protobuf.FieldOptions
-	15, // 18: com.github.joesonw.proto_web.openapi.in_header:extendee -> google.protobuf.FieldOptions
-	15, // 19: com.github.joesonw.proto_web.openapi.in_path:extendee -> google.protobuf.FieldOptions
-	15, // 20: com.github.joesonw.proto_web.openapi.in_cookie:extendee -> google.protobuf.FieldOptions
-	15, // 21: com.github.joesonw.proto_web.openapi.required:extendee -> google.protobuf.FieldOptions
-	15, // 22: com.github.joesonw.proto_web.openapi.deprecated:extendee -> google.protobuf.FieldOptions
-	15, // 23: com.github.joesonw.proto_web.openapi.allow_empty_value:extendee -> google.protobuf.FieldOptions
-	1,  // 24: com.github.joesonw.proto_web.openapi.info:type_name -> com.github.joesonw.proto_web.openapi.Info
-	4,  // 25: com.github.joesonw.proto_web.openapi.servers:type_name -> com.github.joesonw.proto_web.openapi.Server
-	8,  // 26: com.github.joesonw.proto_web.openapi.security:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement
-	0,  // 27: com.github.joesonw.proto_web.openapi.tags:type_name -> com.github.joesonw.proto_web.openapi.Tag
-	7,  // 28: com.github.joesonw.proto_web.openapi.external_docs:type_name -> com.github.joesonw.proto_web.openapi.ExternalDocumentation
-	6,  // 29: com.github.joesonw.proto_web.openapi.path:type_name -> com.github.joesonw.proto_web.openapi.Path
-	30, // [30:30] is the sub-list for method output_type
-	30, // [30:30] is the sub-list for method input_type
-	24, // [24:30] is the sub-list for extension type_name
-	10, // [10:24] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	6,  // 7: com.github.joesonw.proto_web.openapi.Path.additional_bindings:type_name -> com.github.joesonw.proto_web.openapi.Path
+	12, // 8: com.github.joesonw.proto_web.openapi.SecurityRequirement.scopes:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement.ScopesEntry
+	5,  // 9: com.github.joesonw.proto_web.openapi.Server.VariablesEntry.value:type_name -> com.github.joesonw.proto_web.openapi.ServerVariable
+	11, // 10: com.github.joesonw.proto_web.openapi.SecurityRequirement.ScopesEntry.value:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement.Scope
+	13, // 11: com.github.joesonw.proto_web.openapi.info:extendee -> google.protobuf.FileOptions
+	13, // 12: com.github.joesonw.proto_web.openapi.servers:extendee -> google.protobuf.FileOptions
+	13, // 13: com.github.joesonw.proto_web.openapi.security:extendee -> google.protobuf.FileOptions
+	13, // 14: com.github.joesonw.proto_web.openapi.tags:extendee -> google.protobuf.FileOptions
+	13, // 15: com.github.joesonw.proto_web.openapi.external_docs:extendee -> google.protobuf.FileOptions
+	14, // 16: com.github.joesonw.proto_web.openapi.prefix:extendee -> google.protobuf.ServiceOptions
+	15, // 17: com.github.joesonw.proto_web.openapi.path:extendee -> google.protobuf.MethodOptions
+	16, // 18: com.github.joesonw.proto_web.openapi.in_query:extendee -> google.protobuf.FieldOptions
+	16, // 19: com.github.joesonw.proto_web.openapi.in_header:extendee -> google.protobuf.FieldOptions
+	16, // 20: com.github.joesonw.proto_web.openapi.in_path:extendee -> google.protobuf.FieldOptions
+	16, // 21: com.github.joesonw.proto_web.openapi.in_cookie:extendee -> google.protobuf.FieldOptions
+	16, // 22: com.github.joesonw.proto_web.openapi.required:extendee -> google.protobuf.FieldOptions
+	16, // 23: com.github.joesonw.proto_web.openapi.deprecated:extendee -> google.protobuf.FieldOptions
+	16, // 24: com.github.joesonw.proto_web.openapi.allow_empty_value:extendee -> google.protobuf.FieldOptions
+	16, // 25: com.github.joesonw.proto_web.openapi.style:extendee -> google.protobuf.FieldOptions
+	16, // 26: com.github.joesonw.proto_web.openapi.cookie:extendee -> google.protobuf.FieldOptions
+	1,  // 27: com.github.joesonw.proto_web.openapi.info:type_name -> com.github.joesonw.proto_web.openapi.Info
+	4,  // 28: com.github.joesonw.proto_web.openapi.servers:type_name -> com.github.joesonw.proto_web.openapi.Server
+	8,  // 29: com.github.joesonw.proto_web.openapi.security:type_name -> com.github.joesonw.proto_web.openapi.SecurityRequirement
+	0,  // 30: com.github.joesonw.proto_web.openapi.tags:type_name -> com.github.joesonw.proto_web.openapi.Tag
+	7,  // 31: com.github.joesonw.proto_web.openapi.external_docs:type_name -> com.github.joesonw.proto_web.openapi.ExternalDocumentation
+	6,  // 32: com.github.joesonw.proto_web.openapi.path:type_name -> com.github.joesonw.proto_web.openapi.Path
+	9,  // 33: com.github.joesonw.proto_web.openapi.cookie:type_name -> com.github.joesonw.proto_web.openapi.Cookie
+	34, // [34:34] is the sub-list for method output_type
+	34, // [34:34] is the sub-list for method input_type
+	27, // [27:34] is the sub-list for extension type_name
+	11, // [11:27] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_openapi_proto_init() }
@@ -1306,7 +1490,19 @@ func file_openapi_proto_init() {
 				return nil
 			}
 		}
-		file_openapi_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_openapi_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Cookie); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_openapi_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SecurityRequirement_Scope); i {
 			case 0:
 				return &v.state
@@ -1325,8 +1521,8 @@ func file_openapi_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_openapi_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   12,
-			NumExtensions: 14,
+			NumMessages:   13,
+			NumExtensions: 16,
 			NumServices:   0,
 		},
 		GoTypes:           file_openapi_proto_goTypes,
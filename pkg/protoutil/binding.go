@@ -0,0 +1,173 @@
+package protoutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+
+	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
+)
+
+// Binding is a route a method is served at.
+type Binding struct {
+	HttpMethod string
+	// Path is in the form of httprouter, without the custom verb.
+	Path string
+	// OpenAPIPath is in the form of OpenAPI, keeping the custom verb.
+	OpenAPIPath string
+	Verb        string
+	Vars        []PathVar
+	// Body is "*" for the whole request, the name of a field, or "" if the
+	// request has no body.
+	Body string
+	// ResponseBody is the name of the field sent as the response, or "" for
+	// the whole response.
+	ResponseBody string
+	Summary      string
+	ID           string
+	// Status is the HTTP status of successful responses, or 0 for
+	// http.StatusOK.
+	Status int
+	// Location is the template of the Location header of
+	// http.StatusCreated responses, e.g. "/users/{id}", of fields of the
+	// response body.
+	Location string
+}
+
+// Bindings returns routes of method, of either the openapi path annotation or
+// the google.api.http annotation, followed by their additional bindings.
+// Requests of openapi paths without body option have whole bodies for POST,
+// PUT and PATCH. Additional bindings without summary or status share the ones
+// of method, as do the ones of the same status without location, and the
+// status, location and summary of the openapi path, which may have no route
+// for the google.api.http annotation, are of its bindings too, with the
+// operation id on the first binding only.
+func Bindings(method *protogen.Method) ([]Binding, error) {
+	var bindings []Binding
+	primary := proto.GetExtension(method.Desc.Options(), openapi_pb.E_Path).(*openapi_pb.Path)
+	for _, path := range append([]*openapi_pb.Path{primary}, primary.GetAdditionalBindings()...) {
+		if status := path.GetStatus(); status != 0 && (status < 200 || status > 299) {
+			return nil, fmt.Errorf("status %d of method %s of service %s is not 2xx", status, method.Desc.Name(), method.Parent.Desc.Name())
+		}
+	}
+	if _, route := pathMethodAndPath(primary); route == "" {
+		if rule := HttpRule(method); rule != nil {
+			for i, rule := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				httpMethod, path := ruleMethodAndPath(rule)
+				if path == "" {
+					return nil, fmt.Errorf("no valid method found for method %s of service %s", method.Desc.Name(), method.Parent.Desc.Name())
+				}
+				b := Binding{
+					HttpMethod:   httpMethod,
+					Body:         rule.GetBody(),
+					ResponseBody: rule.GetResponseBody(),
+					Summary:      primary.GetSummary(),
+					Status:       int(primary.GetStatus()),
+					Location:     primary.GetLocation(),
+				}
+				// operation ids are unique, the additional bindings have none
+				if i == 0 {
+					b.ID = primary.GetId()
+				}
+				if err := b.setPath(path); err != nil {
+					return nil, fmt.Errorf("method %s of service %s: %w", method.Desc.Name(), method.Parent.Desc.Name(), err)
+				}
+				bindings = append(bindings, b)
+			}
+			return bindings, nil
+		}
+	}
+	for _, path := range append([]*openapi_pb.Path{primary}, primary.GetAdditionalBindings()...) {
+		httpMethod, route := pathMethodAndPath(path)
+		if route == "" {
+			return nil, fmt.Errorf("no valid method found for method %s of service %s", method.Desc.Name(), method.Parent.Desc.Name())
+		}
+		b := Binding{
+			HttpMethod: httpMethod,
+			Body:       path.GetBody(),
+			Summary:    path.GetSummary(),
+			ID:         path.GetId(),
+			Status:     int(path.GetStatus()),
+			Location:   path.GetLocation(),
+		}
+		if err := b.setPath(route); err != nil {
+			return nil, fmt.Errorf("method %s of service %s: %w", method.Desc.Name(), method.Parent.Desc.Name(), err)
+		}
+		if b.Summary == "" {
+			b.Summary = primary.GetSummary()
+		}
+		if b.Status == 0 {
+			b.Status = int(primary.GetStatus())
+		}
+		if b.Location == "" && b.Status == int(primary.GetStatus()) {
+			b.Location = primary.GetLocation()
+		}
+		if b.Body == "" && (httpMethod == http.MethodPost || httpMethod == http.MethodPut || httpMethod == http.MethodPatch) {
+			b.Body = "*"
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
+
+// setPath sets paths, verb and variables of b from path.
+func (b *Binding) setPath(path string) error {
+	var err error
+	b.Path, b.OpenAPIPath, b.Verb, b.Vars, err = parsePath(path)
+	return err
+}
+
+func pathMethodAndPath(path *openapi_pb.Path) (string, string) {
+	if path.GetGet() != "" {
+		return http.MethodGet, path.GetGet()
+	} else if path.GetPut() != "" {
+		return http.MethodPut, path.GetPut()
+	} else if path.GetPost() != "" {
+		return http.MethodPost, path.GetPost()
+	} else if path.GetDelete() != "" {
+		return http.MethodDelete, path.GetDelete()
+	} else if path.GetOptions() != "" {
+		return http.MethodOptions, path.GetOptions()
+	} else if path.GetHead() != "" {
+		return http.MethodHead, path.GetHead()
+	} else if path.GetPatch() != "" {
+		return http.MethodPatch, path.GetPatch()
+	} else if path.GetTrace() != "" {
+		return http.MethodTrace, path.GetTrace()
+	} else if path.GetStream() != "" {
+		return http.MethodConnect, path.GetStream()
+	} else {
+		return "", ""
+	}
+}
+
+// HttpRule returns the google.api.http annotation of method, or nil.
+func HttpRule(method *protogen.Method) *annotations.HttpRule {
+	if !proto.HasExtension(method.Desc.Options(), annotations.E_Http) {
+		return nil
+	}
+	return proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+}
+
+func ruleMethodAndPath(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
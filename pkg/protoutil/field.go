@@ -0,0 +1,95 @@
+package protoutil
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	openapi_pb "github.com/joesonw/proto-web/pbgo/openapi"
+)
+
+// FindField returns the field of message by its name, or nil.
+func FindField(message *protogen.Message, name string) *protogen.Field {
+	for _, field := range message.Fields {
+		if string(field.Desc.Name()) == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// HasParamAnnotation reports whether field is annotated to be in path, query,
+// header or cookie.
+func HasParamAnnotation(field *protogen.Field) bool {
+	options := field.Desc.Options()
+	return proto.HasExtension(options, openapi_pb.E_InQuery) ||
+		proto.HasExtension(options, openapi_pb.E_InPath) ||
+		proto.HasExtension(options, openapi_pb.E_InHeader) ||
+		proto.HasExtension(options, openapi_pb.E_InCookie)
+}
+
+// IsScalarField reports whether field is a singular value which can be parsed
+// from a parameter, including well known types of IsWellKnownParam.
+func IsScalarField(field *protogen.Field) bool {
+	if field.Desc.IsList() || field.Desc.IsMap() {
+		return false
+	}
+	switch field.Desc.Kind() {
+	case protoreflect.MessageKind:
+		return IsWellKnownParam(field.Message)
+	case protoreflect.GroupKind:
+		return false
+	}
+	return true
+}
+
+// IsWellKnownParam reports whether message is a well known type parsed from the
+// JSON string form of a parameter.
+func IsWellKnownParam(message *protogen.Message) bool {
+	switch message.Desc.FullName() {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.DoubleValue", "google.protobuf.FloatValue",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return true
+	}
+	return false
+}
+
+// IsQueryField reports whether field can be bound from query without
+// annotation, which is a scalar, a repeated scalar or a map of string keys and
+// scalar values.
+func IsQueryField(field *protogen.Field) bool {
+	if field.Desc.IsMap() {
+		return field.Message.Fields[0].Desc.Kind() == protoreflect.StringKind && IsScalarField(field.Message.Fields[1])
+	}
+	switch field.Desc.Kind() {
+	case protoreflect.MessageKind:
+		return IsWellKnownParam(field.Message)
+	case protoreflect.GroupKind:
+		return false
+	}
+	return true
+}
+
+// IsNestedQueryField reports whether fields of field can be bound from query by
+// their dotted path, which is a singular message other than well known types.
+func IsNestedQueryField(field *protogen.Field) bool {
+	return field.Desc.Kind() == protoreflect.MessageKind && !field.Desc.IsList() && !field.Desc.IsMap() &&
+		field.Message.Desc.ParentFile().Package() != "google.protobuf"
+}
+
+// IsNestedMessage reports whether message is root or any of the messages of
+// fields.
+func IsNestedMessage(root *protogen.Message, fields []*protogen.Field, message *protogen.Message) bool {
+	if message.Desc.FullName() == root.Desc.FullName() {
+		return true
+	}
+	for _, field := range fields {
+		if field.Message.Desc.FullName() == message.Desc.FullName() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package protoutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PathVar is a variable of a path, bound to a field of the request, e.g.
+// "book.name" of "/v1/{book.name=shelves/*/books/*}".
+type PathVar struct {
+	Field string
+	// Segments are literals, or names of httprouter params prefixed with ':'
+	// or '*', whose values are joined with '/'.
+	Segments []string
+	// Pattern is the regular expression of values spanning more than a
+	// segment, e.g. "^shelves/[^/]+/books/[^/]+$".
+	Pattern string
+	// Template tells whether it's from a template, rather than a param of
+	// httprouter written in the path, which does not have to match a field.
+	Template bool
+}
+
+// parsePath converts path templates to the form of httprouter and of OpenAPI,
+// e.g. "/v1/{book.name=shelves/*/books/*}:move" to
+// "/v1/shelves/:book.name/books/:book.name.1" and "/v1/{book.name}:move" with
+// the custom verb "move", and "/files/{path=**}" to "/files/*path" and
+// "/files/{path}". "{name}" is short for "{name=*}". Params of httprouter in
+// path are variables as well.
+func parsePath(path string) (string, string, string, []PathVar, error) {
+	path, verb := splitVerb(path)
+	var out, openAPI strings.Builder
+	var vars []PathVar
+	for path != "" {
+		i := strings.IndexByte(path, '{')
+		if i < 0 {
+			i = len(path)
+		}
+		for _, segment := range strings.Split(path[:i], "/") {
+			if segment == "*" || segment == "**" {
+				return "", "", "", nil, fmt.Errorf("path %s: wildcards must be in variables", path)
+			}
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				vars = append(vars, PathVar{Field: segment[1:], Segments: []string{segment}})
+			}
+		}
+		out.WriteString(path[:i])
+		openAPI.WriteString(path[:i])
+		path = path[i:]
+		if path == "" {
+			break
+		}
+
+		j := strings.IndexByte(path, '}')
+		if j < 0 {
+			return "", "", "", nil, fmt.Errorf("path %s: unclosed variable", path)
+		}
+		v := PathVar{Field: path[1:j], Template: true}
+		pattern := "*"
+		if k := strings.IndexByte(v.Field, '='); k >= 0 {
+			v.Field, pattern = v.Field[:k], v.Field[k+1:]
+		}
+		path = path[j+1:]
+		n := 0
+		var expr []string
+		for k, segment := range strings.Split(pattern, "/") {
+			if k > 0 {
+				out.WriteByte('/')
+			}
+			switch segment {
+			case "*", "**":
+				name := v.Field
+				if n > 0 {
+					name = fmt.Sprintf("%s.%d", v.Field, n)
+				}
+				n++
+				if segment == "**" {
+					if path != "" || k != strings.Count(pattern, "/") {
+						return "", "", "", nil, fmt.Errorf("path %s: ** must be at the end", v.Field)
+					}
+					name = "*" + name
+					expr = append(expr, ".*")
+				} else {
+					name = ":" + name
+					expr = append(expr, "[^/]+")
+				}
+				out.WriteString(name)
+				v.Segments = append(v.Segments, name)
+			default:
+				out.WriteString(segment)
+				v.Segments = append(v.Segments, segment)
+				expr = append(expr, regexp.QuoteMeta(segment))
+			}
+		}
+		if len(expr) > 1 || expr[0] != "[^/]+" {
+			v.Pattern = "^" + strings.Join(expr, "/") + "$"
+		}
+		openAPI.WriteString("{" + v.Field + "}")
+		vars = append(vars, v)
+	}
+	if verb != "" {
+		openAPI.WriteString(":" + verb)
+	}
+	return out.String(), openAPI.String(), verb, vars, nil
+}
+
+// splitVerb splits the custom verb from the last segment of path, e.g.
+// "/v1/{name}:cancel" is split into "/v1/{name}" and "cancel".
+func splitVerb(path string) (string, string) {
+	segment := path[strings.LastIndexByte(path, '/')+1:]
+	i := strings.LastIndexByte(segment, ':')
+	if i <= 0 {
+		return path, ""
+	}
+	return path[:len(path)-len(segment)+i], segment[i+1:]
+}
+
+// ResolveFields returns fields of message along the dotted path of v, or nil
+// if fields of a param of httprouter are not found, or are annotated to be
+// bound from elsewhere. The last field must be a scalar.
+func (v PathVar) ResolveFields(message *protogen.Message) ([]*protogen.Field, error) {
+	var fields []*protogen.Field
+	names := strings.Split(v.Field, ".")
+	for i, name := range names {
+		field := FindField(message, name)
+		if field == nil {
+			if !v.Template {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("path variable %s: field %s is not found in %s", v.Field, name, message.Desc.Name())
+		}
+		if i == 0 && HasParamAnnotation(field) {
+			return nil, nil
+		}
+		if i < len(names)-1 {
+			if field.Desc.Kind() != protoreflect.MessageKind || field.Desc.IsList() || field.Desc.IsMap() {
+				return nil, fmt.Errorf("path variable %s: field %s of %s is not a message", v.Field, name, message.Desc.Name())
+			}
+			message = field.Message
+		} else if !IsScalarField(field) {
+			return nil, fmt.Errorf("path variable %s: field %s of %s is not a scalar", v.Field, name, message.Desc.Name())
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
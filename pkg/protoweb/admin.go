@@ -0,0 +1,147 @@
+package protoweb
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminHandler returns an HTTP handler for operators to list and close streams.
+// It does no authorization of its own, and is to be mounted behind it, e.g.
+//
+//	mux.Handle("/admin/", authorized(http.StripPrefix("/admin", s.AdminHandler())))
+//
+// GET /streams lists streams as a JSON array, filtered by the method and
+// principal query parameters if set. DELETE /streams/:id closes a stream, and
+// DELETE /streams closes every stream matched by at least one of those
+// filters. The final status of closed streams is given by the code and message
+// query parameters, e.g. code=PERMISSION_DENIED, defaults to Unavailable.
+func (s *Server) AdminHandler() http.Handler {
+	router := httprouter.New()
+	router.GET("/streams", s.processListStreams)
+	router.DELETE("/streams", s.processCloseStreams)
+	router.DELETE("/streams/:id", s.processCloseStream)
+	return router
+}
+
+// adminStream is a stream listed by AdminHandler.
+type adminStream struct {
+	ID               uint64    `json:"id"`
+	Method           string    `json:"method"`
+	Transport        string    `json:"transport"`
+	Peer             string    `json:"peer,omitempty"`
+	Principal        string    `json:"principal,omitempty"`
+	StartTime        time.Time `json:"start_time"`
+	MessagesSent     uint64    `json:"messages_sent"`
+	MessagesReceived uint64    `json:"messages_received"`
+}
+
+func (s *Server) processListStreams(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	match := adminStreamFilter(r)
+	streams := []adminStream{}
+	for _, info := range s.Streams() {
+		if match != nil && !match(info) {
+			continue
+		}
+		as := adminStream{
+			ID:               info.ID,
+			Method:           info.Method,
+			Transport:        info.Transport,
+			StartTime:        info.StartTime,
+			MessagesSent:     info.MessagesSent,
+			MessagesReceived: info.MessagesReceived,
+		}
+		if info.Peer != nil && info.Peer.Addr != nil {
+			as.Peer = info.Peer.Addr.String()
+		}
+		as.Principal, _ = PrincipalFromContext(info.Context)
+		streams = append(streams, as)
+	}
+	writeAdminResponse(w, http.StatusOK, streams)
+}
+
+func (s *Server) processCloseStreams(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	match := adminStreamFilter(r)
+	if match == nil {
+		writeStatusResponse(w, http.StatusBadRequest, status.New(codes.InvalidArgument, "method or principal is required"))
+		return
+	}
+	st, ok := adminCloseStatus(w, r)
+	if !ok {
+		return
+	}
+	n := s.CloseStreams(match, st)
+	writeAdminResponse(w, http.StatusOK, map[string]int{"closed": n})
+}
+
+func (s *Server) processCloseStream(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	id, err := strconv.ParseUint(params.ByName("id"), 10, 64)
+	if err != nil {
+		writeStatusResponse(w, http.StatusBadRequest, status.Newf(codes.InvalidArgument, "malformed stream id %q", params.ByName("id")))
+		return
+	}
+	st, ok := adminCloseStatus(w, r)
+	if !ok {
+		return
+	}
+	if err := s.CloseStream(id, st); err != nil {
+		writeStatusResponse(w, httpStatusFromError(err), status.Convert(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminStreamFilter matches streams by the method and principal query
+// parameters, or returns nil if neither is set.
+func adminStreamFilter(r *http.Request) func(StreamInfo) bool {
+	query := r.URL.Query()
+	method, principal := query.Get("method"), query.Get("principal")
+	if method == "" && principal == "" {
+		return nil
+	}
+	return func(info StreamInfo) bool {
+		if method != "" && info.Method != method {
+			return false
+		}
+		if principal != "" {
+			if p, _ := PrincipalFromContext(info.Context); p != principal {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// adminCloseStatus returns the final status of streams closed by r. The
+// request is rejected if it returns false.
+func adminCloseStatus(w http.ResponseWriter, r *http.Request) (*status.Status, bool) {
+	query := r.URL.Query()
+	code := codes.Unavailable
+	if name := query.Get("code"); name != "" {
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(name))); err != nil {
+			writeStatusResponse(w, http.StatusBadRequest, status.Newf(codes.InvalidArgument, "invalid code %q", name))
+			return nil, false
+		}
+	}
+	message := query.Get("message")
+	if message == "" {
+		message = "stream is closed by the server"
+	}
+	return status.New(code, message), true
+}
+
+func writeAdminResponse(w http.ResponseWriter, httpStatus int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeStatusResponse(w, http.StatusInternalServerError, status.New(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(b)
+}
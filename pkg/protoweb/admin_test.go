@@ -0,0 +1,55 @@
+package protoweb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gobwas/ws"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAdminHandler(t *testing.T) {
+	s, ts := newTestServer(t, StreamAuth(func(ctx context.Context, r *http.Request, token string) (context.Context, error) {
+		return WithPrincipal(ctx, "user-"+token), nil
+	}))
+	admin := httptest.NewServer(s.AdminHandler())
+	defer admin.Close()
+
+	c := dialTestConn(t, ts, "/echo", http.Header{"Authorization": {"Bearer 1"}})
+	c.write(ws.OpText, []byte(`"a"`))
+	if b, _, err := c.read(); err != nil || string(b) != `"a"` {
+		t.Fatalf("message = %q, %v", b, err)
+	}
+
+	resp, err := http.Get(admin.URL + "/streams?principal=user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streams []adminStream
+	err = json.NewDecoder(resp.Body).Decode(&streams)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 1 || streams[0].Method != "/test.Test/Echo" || streams[0].Transport != TransportWebSocket || streams[0].MessagesSent != 1 {
+		t.Fatalf("streams = %+v, want the echo stream", streams)
+	}
+
+	r, _ := http.NewRequest(http.MethodDelete, admin.URL+"/streams/"+strconv.FormatUint(streams[0].ID, 10)+"?code=PERMISSION_DENIED&message=evicted", nil)
+	resp, err = http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	frame := c.readFrame()
+	if st := frameStatus(t, frame); st.Code() != codes.PermissionDenied || st.Message() != "evicted" {
+		t.Errorf("status = %v, want %s evicted", st, codes.PermissionDenied)
+	}
+}
@@ -0,0 +1,227 @@
+package protoweb
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultTokenProtocolPrefix = "bearer."
+
+// StreamAuthFunc runs before a stream is served, with the token passed by the
+// client, which is empty if there is none. The returned context becomes the
+// base of the stream context. An error rejects the request before the upgrade,
+// with the HTTP status of the error if it has one, or the one mapped from its
+// gRPC code.
+//
+// The principal of the stream is the token, unless the returned context
+// carries one set by WithPrincipal. A stream outliving its connection is only
+// continued by requests of the same principal.
+type StreamAuthFunc func(ctx context.Context, r *http.Request, token string) (context.Context, error)
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal, which identifies who
+// a stream is authorized for, e.g. a user id.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of a stream, set by
+// WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey{}).(string)
+	return principal, ok
+}
+
+// StreamAuth sets the hook authorizing streams, on WebSocket, multiplexed and
+// long-polling transports.
+func StreamAuth(fn StreamAuthFunc) ServerOption {
+	return func(o *serverOptions) {
+		o.streamAuth = fn
+	}
+}
+
+// StreamAllowedOrigins sets origins allowed to open WebSocket streams, as
+// patterns of path.Match, e.g. "https://*.example.com", or "*" for any origin.
+// Without it, only the origin of the same host is allowed. Requests without
+// an Origin header, which do not come from browsers, are always allowed.
+func StreamAllowedOrigins(origins ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.allowedOrigins = origins
+	}
+}
+
+// StreamToken sets where the token passed to StreamAuthFunc is taken from,
+// besides the Authorization header with the Bearer scheme: the query parameter
+// queryParam, e.g. "access_token", which is disabled by default as query
+// strings end up in access logs, and the Sec-WebSocket-Protocol value prefixed
+// with protocolPrefix, defaults to "bearer.". Browsers cannot set headers of
+// WebSocket requests, they may offer e.g. ["v1", "bearer.<token>"] as
+// protocols, see StreamProtocols for which one is selected. Empty values
+// disable the corresponding source.
+func StreamToken(queryParam, protocolPrefix string) ServerOption {
+	return func(o *serverOptions) {
+		o.tokenQueryParam = queryParam
+		o.tokenProtocolPrefix = protocolPrefix
+	}
+}
+
+// StreamProtocols sets WebSocket subprotocols the server speaks. The first one
+// offered by the client is selected, or the one carrying the token if it is
+// the only one offered, as browsers fail the handshake if none of the offered
+// protocols is selected.
+func StreamProtocols(protocols ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.protocols = protocols
+	}
+}
+
+// authorizeStream rejects streams once the server is stopped, checks the
+// origin of upgrades and runs the auth hook. The request is rejected if it
+// returns false.
+func (s *Server) authorizeStream(ctx context.Context, w http.ResponseWriter, r *http.Request, upgrade bool) (context.Context, bool) {
+	select {
+	case <-s.done:
+		writeStatusResponse(w, http.StatusServiceUnavailable, status.Convert(ErrServerStopped))
+		return nil, false
+	default:
+	}
+	if upgrade && !s.opts.originAllowed(r) {
+		writeStatusResponse(w, http.StatusForbidden, status.Newf(codes.PermissionDenied, "origin %q is not allowed", r.Header.Get("Origin")))
+		return nil, false
+	}
+	return s.authenticateStream(ctx, w, r)
+}
+
+// authenticateStream runs the auth hook, and sets the principal of the stream
+// on ctx. The request is rejected if it returns false.
+func (s *Server) authenticateStream(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, bool) {
+	if s.opts.streamAuth == nil {
+		return ctx, true
+	}
+
+	token := s.opts.streamToken(r)
+	ctx, err := s.opts.streamAuth(ctx, r, token)
+	if err != nil {
+		// the HTTP status is lost once converted to a gRPC status
+		httpStatus := httpStatusFromError(err)
+		writeStatusResponse(w, httpStatus, status.Convert(toRPCErr(err)))
+		return nil, false
+	}
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		ctx = WithPrincipal(ctx, token)
+	}
+	return ctx, true
+}
+
+func (o *serverOptions) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if o.allowedOrigins == nil {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range o.allowedOrigins {
+		if pattern == "*" {
+			// path.Match never matches "/" of the scheme with "*"
+			return true
+		}
+		if ok, _ := path.Match(strings.ToLower(pattern), origin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *serverOptions) streamToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return auth[7:]
+	}
+	if o.tokenQueryParam != "" {
+		if token := r.URL.Query().Get(o.tokenQueryParam); token != "" {
+			return token
+		}
+	}
+	if o.tokenProtocolPrefix != "" {
+		for _, p := range offeredProtocols(r) {
+			if strings.HasPrefix(p, o.tokenProtocolPrefix) {
+				return p[len(o.tokenProtocolPrefix):]
+			}
+		}
+	}
+	return ""
+}
+
+// selectProtocol returns the Protocol func of upgraders of r, see
+// StreamProtocols.
+func (o *serverOptions) selectProtocol(r *http.Request) func(string) bool {
+	offered := offeredProtocols(r)
+	selected := ""
+find:
+	for _, p := range offered {
+		for _, sp := range o.protocols {
+			if p == sp {
+				selected = p
+				break find
+			}
+		}
+	}
+	if selected == "" && len(offered) == 1 && o.tokenProtocolPrefix != "" && strings.HasPrefix(offered[0], o.tokenProtocolPrefix) {
+		selected = offered[0]
+	}
+	return func(p string) bool {
+		return selected != "" && p == selected
+	}
+}
+
+func offeredProtocols(r *http.Request) []string {
+	var protocols []string
+	for _, h := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(h, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				protocols = append(protocols, p)
+			}
+		}
+	}
+	return protocols
+}
+
+// detachedContext keeps values of its parent, but is never done. Streams
+// outliving the request they were opened with are based on it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detachContext(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	if _, ok := key.(causeKey); ok {
+		// the parent being done is not a cause of the detached one
+		return nil
+	}
+	return c.parent.Value(key)
+}
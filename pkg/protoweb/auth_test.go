@@ -0,0 +1,97 @@
+package protoweb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	tests := []struct {
+		origins []string
+		origin  string
+		allowed bool
+	}{
+		{nil, "", true},
+		{nil, "http://example.com", true},
+		{nil, "https://other.com", false},
+		{[]string{"*"}, "https://app.other.com", true},
+		{[]string{"*"}, "http://localhost:3000", true},
+		{[]string{"https://*.example.com"}, "https://app.example.com", true},
+		{[]string{"https://*.example.com"}, "https://APP.Example.com", true},
+		{[]string{"https://*.example.com"}, "https://example.com", false},
+		{[]string{"https://*.example.com"}, "http://app.example.com", false},
+		{[]string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{[]string{"https://*.example.com"}, "https://app.other.com", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/stream", nil)
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		o := &serverOptions{allowedOrigins: test.origins}
+		if allowed := o.originAllowed(r); allowed != test.allowed {
+			t.Errorf("origins %q, origin %q: allowed = %v, want %v", test.origins, test.origin, allowed, test.allowed)
+		}
+	}
+}
+
+func TestAuthorizeStreamHTTPStatus(t *testing.T) {
+	s := NewServer(StreamAuth(func(ctx context.Context, r *http.Request, token string) (context.Context, error) {
+		return nil, NewHTTPError(http.StatusUnauthorized, errors.New("no token"))
+	}))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/stream", nil)
+	if _, ok := s.authorizeStream(r.Context(), w, r, true); ok {
+		t.Fatal("stream is authorized")
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSelectProtocol(t *testing.T) {
+	tests := []struct {
+		protocols []string
+		offered   string
+		selected  string
+	}{
+		{nil, "", ""},
+		{nil, "v1", ""},
+		{nil, "bearer.t", "bearer.t"},
+		{nil, "v1, bearer.t", ""},
+		{[]string{"v1"}, "v1, bearer.t", "v1"},
+		{[]string{"v1", "v2"}, "v2, v1", "v2"},
+		{[]string{"v1"}, "v2", ""},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/stream", nil)
+		if test.offered != "" {
+			r.Header.Set("Sec-WebSocket-Protocol", test.offered)
+		}
+		o := &serverOptions{protocols: test.protocols, tokenProtocolPrefix: defaultTokenProtocolPrefix}
+		protocol := o.selectProtocol(r)
+		selected := ""
+		for _, p := range offeredProtocols(r) {
+			if protocol(p) {
+				selected = p
+				break
+			}
+		}
+		if selected != test.selected {
+			t.Errorf("protocols %q, offered %q: selected = %q, want %q", test.protocols, test.offered, selected, test.selected)
+		}
+	}
+}
+
+func TestStreamTokenQueryParam(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/stream?access_token=t", nil)
+	if token := NewServer().opts.streamToken(r); token != "" {
+		t.Errorf("token = %q, want none by default", token)
+	}
+	if token := NewServer(StreamToken("access_token", "")).opts.streamToken(r); token != "t" {
+		t.Errorf("token = %q, want %q", token, "t")
+	}
+}
@@ -0,0 +1,106 @@
+package protoweb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gobwas/ws/wsutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrServerStopped is the cause of streams closed by Server.Stop.
+var ErrServerStopped = status.Error(codes.Unavailable, "server is stopped")
+
+// errStreamCancelled is the cause of streams cancelled by the client.
+var errStreamCancelled = status.Error(codes.Canceled, "stream is cancelled by client")
+
+// CloseError is the cause of a stream whose connection was closed by the
+// client with a close frame.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("connection closed by client: %d %s", e.Code, e.Reason)
+}
+
+func (e *CloseError) GRPCStatus() *status.Status {
+	return status.New(codes.Canceled, e.Error())
+}
+
+// Cause returns why the stream of ctx was closed: a *CloseError, a status
+// error of the transport, ErrServerStopped, or ctx.Err() if the stream has
+// finished or the reason is unknown. It returns nil while ctx is not done.
+func Cause(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	if c, ok := ctx.Value(causeKey{}).(*streamCause); ok {
+		if err := c.get(); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// connCause converts the error ending the read loop of a connection into the
+// cause of its streams.
+func connCause(err error) error {
+	if _, ok := status.FromError(err); ok {
+		// the connection is closed for a reason of its own
+		return err
+	}
+	if ce, ok := err.(wsutil.ClosedError); ok {
+		return &CloseError{
+			Code:   int(ce.Code),
+			Reason: ce.Reason,
+		}
+	}
+	return status.Errorf(codes.Unavailable, "connection lost: %s", err)
+}
+
+type causeKey struct{}
+
+// cancelCauseFunc cancels a context, recording cause as the reason. The first
+// cause wins, nil means the stream has finished.
+type cancelCauseFunc func(cause error)
+
+type streamCause struct {
+	parent *streamCause
+
+	mu  sync.Mutex
+	err error
+}
+
+func withCancelCause(parent context.Context) (context.Context, cancelCauseFunc) {
+	c := &streamCause{}
+	c.parent, _ = parent.Value(causeKey{}).(*streamCause)
+	ctx, cancel := context.WithCancel(context.WithValue(parent, causeKey{}, c))
+	return ctx, func(cause error) {
+		if cause == nil {
+			cause = context.Canceled
+		}
+		c.mu.Lock()
+		if c.err == nil {
+			c.err = cause
+		}
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// get returns the cause of c, or of the closest parent having one.
+func (c *streamCause) get() error {
+	for ; c != nil; c = c.parent {
+		c.mu.Lock()
+		err := c.err
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
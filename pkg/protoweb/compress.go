@@ -0,0 +1,171 @@
+package protoweb
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+
+	"github.com/gobwas/httphead"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultCompressionMaxMessageSize = 4 << 20
+
+var (
+	// deflateTail ends every compressed message, it is stripped when sent.
+	deflateTail = []byte{0, 0, 0xff, 0xff}
+	// inflateTail is appended to received messages, the final empty block
+	// lets the decompressor reach EOF.
+	inflateTail = []byte{0, 0, 0xff, 0xff, 1, 0, 0, 0xff, 0xff}
+)
+
+// StreamCompressionParams controls permessage-deflate compression of
+// WebSocket streams, it is used when offered by the client.
+type StreamCompressionParams struct {
+	// Level is the level of compress/flate, zero means
+	// flate.DefaultCompression.
+	Level int
+	// Threshold is the size under which messages are sent uncompressed.
+	Threshold int
+	// ServerNoContextTakeover resets the compression context after every
+	// sent message, trading ratio for memory.
+	ServerNoContextTakeover bool
+	// ClientNoContextTakeover asks the client to do the same for messages
+	// it sends.
+	ClientNoContextTakeover bool
+	// MaxMessageSize is the size a received message may be decompressed
+	// to, the connection is closed with ResourceExhausted once exceeded.
+	// Defaults to 4 MiB.
+	MaxMessageSize int
+}
+
+// StreamCompression enables permessage-deflate compression of WebSocket
+// streams, including multiplexed connections.
+func StreamCompression(cp StreamCompressionParams) ServerOption {
+	if cp.Level == 0 {
+		cp.Level = flate.DefaultCompression
+	}
+	if cp.MaxMessageSize <= 0 {
+		cp.MaxMessageSize = defaultCompressionMaxMessageSize
+	}
+	return func(o *serverOptions) {
+		o.compression = &cp
+	}
+}
+
+// negotiate accepts the first permessage-deflate offer it can satisfy, and
+// stores the agreed parameters in accepted.
+func (cp *StreamCompressionParams) negotiate(accepted **wsflate.Parameters) func(httphead.Option) (httphead.Option, error) {
+	return func(opt httphead.Option) (httphead.Option, error) {
+		if *accepted != nil || !bytes.Equal(opt.Name, wsflate.ExtensionNameBytes) {
+			return httphead.Option{}, nil
+		}
+		var offer wsflate.Parameters
+		if err := offer.Parse(opt); err != nil {
+			return httphead.Option{}, nil
+		}
+		// compress/flate always uses the largest window
+		if offer.ServerMaxWindowBits.Defined() && offer.ServerMaxWindowBits != 15 {
+			return httphead.Option{}, nil
+		}
+		params := &wsflate.Parameters{
+			ServerNoContextTakeover: cp.ServerNoContextTakeover || offer.ServerNoContextTakeover,
+			ClientNoContextTakeover: cp.ClientNoContextTakeover || offer.ClientNoContextTakeover,
+		}
+		*accepted = params
+		return params.Option(), nil
+	}
+}
+
+// deflater compresses and decompresses messages of a connection. Writes and
+// reads are serialized by the connection, so they need no locking here.
+type deflater struct {
+	level            int
+	threshold        int
+	maxSize          int
+	serverNoTakeover bool
+	clientNoTakeover bool
+
+	wbuf bytes.Buffer
+	fw   *flate.Writer
+	fr   io.ReadCloser
+	dict []byte
+}
+
+func newDeflater(cp *StreamCompressionParams, params *wsflate.Parameters) *deflater {
+	if cp == nil || params == nil {
+		return nil
+	}
+	return &deflater{
+		level:            cp.Level,
+		threshold:        cp.Threshold,
+		maxSize:          cp.MaxMessageSize,
+		serverNoTakeover: params.ServerNoContextTakeover,
+		clientNoTakeover: params.ClientNoContextTakeover,
+	}
+}
+
+// compress returns p compressed, which is valid until the next call.
+func (d *deflater) compress(p []byte) ([]byte, error) {
+	d.wbuf.Reset()
+	if d.fw == nil {
+		fw, err := flate.NewWriter(&d.wbuf, d.level)
+		if err != nil {
+			return nil, err
+		}
+		d.fw = fw
+	} else if d.serverNoTakeover {
+		d.fw.Reset(&d.wbuf)
+	}
+	if _, err := d.fw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := d.fw.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(d.wbuf.Bytes(), deflateTail), nil
+}
+
+func (d *deflater) decompress(p []byte) ([]byte, error) {
+	src := io.MultiReader(bytes.NewReader(p), bytes.NewReader(inflateTail))
+	if d.fr == nil {
+		d.fr = flate.NewReaderDict(src, d.dict)
+	} else if err := d.fr.(flate.Resetter).Reset(src, d.dict); err != nil {
+		return nil, err
+	}
+	// a small message may be decompressed to a huge one
+	b, err := ioutil.ReadAll(io.LimitReader(d.fr, int64(d.maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > d.maxSize {
+		return nil, status.Errorf(codes.ResourceExhausted, "decompressed message is larger than %d bytes", d.maxSize)
+	}
+	if !d.clientNoTakeover {
+		// the window of the next message
+		d.dict = append(d.dict, b...)
+		if n := len(d.dict) - wsflate.MaxLZ77WindowSize; n > 0 {
+			d.dict = append(d.dict[:0], d.dict[n:]...)
+		}
+	}
+	return b, nil
+}
+
+// writeMessage writes a server message, compressed if d is not nil.
+func writeMessage(w io.Writer, op ws.OpCode, b []byte, d *deflater) error {
+	if d == nil || !op.IsData() || len(b) < d.threshold {
+		return wsutil.WriteServerMessage(w, op, b)
+	}
+	p, err := d.compress(b)
+	if err != nil {
+		return err
+	}
+	frame := ws.NewFrame(op, true, p)
+	frame.Header.Rsv = ws.Rsv(true, false, false)
+	return ws.WriteFrame(w, frame)
+}
@@ -21,18 +21,39 @@ type MethodDesc struct {
 	MethodName string
 	Path       string
 	HttpMethod string
-	Handler    methodHandler
+	// Verb is the custom verb of google.api.http rules, e.g. "cancel" for
+	// "/v1/{name}:cancel", the path is then "/v1/:name".
+	Verb string
+	// Status is the HTTP status of successful responses, http.StatusOK if 0.
+	// Responses with http.StatusNoContent have no body.
+	Status int
+	// Location is the template of the Location header of http.StatusCreated
+	// responses, e.g. "/users/{id}", where {id} is the path escaped value of
+	// field id of the response.
+	Location string
+	Handler  methodHandler
 }
 
+// streamBinder binds fields of a stream request from path, query, header and
+// cookie of the upgrade request.
+type streamBinder func(m interface{}, r *http.Request, params httprouter.Params) error
+
+// streamChunker returns the bytes of a chunk of a download. Response headers
+// are set on h from the first chunk, h is nil for the rest.
+type streamChunker func(m interface{}, h http.Header) []byte
+
 type StreamDesc struct {
 	StreamName    string
 	Path          string
 	Handler       grpc.StreamHandler
 	ServerStreams bool
 	ClientStreams bool
+	Binder        streamBinder
+	Chunk         streamChunker
 }
 
 type serviceInfo struct {
+	name        string
 	serviceImpl interface{}
 	methods     map[string]*MethodDesc
 	streams     map[string]*StreamDesc
@@ -0,0 +1,224 @@
+package protoweb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const defaultDownloadContentType = "application/octet-stream"
+
+// processDownloadRequest serves a server-only stream marked as download by a
+// plain GET, writing bytes of every message to a chunked response. Header
+// metadata and headers set from the first message are sent as response
+// headers, defaulting Content-Type to application/octet-stream, and trailer
+// metadata as HTTP trailers.
+//
+// A stream failing before its first message is replied with the status as
+// usual. Once the response has started, a failure aborts it, so that clients
+// see a truncated transfer instead of a complete file.
+func (s *Server) processDownloadRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, sd *StreamDesc) {
+	ctx, ok := s.authorizeStream(ctx, w, r, false)
+	if !ok {
+		return
+	}
+	ds := newDownloadStream(ctx, s, sd, w, r, params)
+	err := s.handleStream(si, sd, ds)
+	st, _ := status.FromError(toRPCErr(err))
+	ds.finish(st)
+}
+
+type downloadStream struct {
+	ctx      context.Context
+	cancel   cancelCauseFunc
+	desc     *StreamDesc
+	w        http.ResponseWriter
+	r        *http.Request
+	params   httprouter.Params
+	counters messageCounters
+
+	mu         sync.Mutex
+	header     metadata.MD
+	headerSent bool
+	trailer    metadata.MD
+	recvBound  bool
+	written    bool
+	done       bool
+	truncated  bool
+}
+
+func newDownloadStream(ctx context.Context, s *Server, sd *StreamDesc, w http.ResponseWriter, r *http.Request, params httprouter.Params) *downloadStream {
+	ds := &downloadStream{
+		desc:    sd,
+		w:       w,
+		r:       r,
+		params:  params,
+		header:  metadata.MD{},
+		trailer: metadata.MD{},
+	}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, &serverStreamTransport{
+		method: sd.StreamName,
+		ss:     ds,
+	})
+	ds.ctx, ds.cancel = withCancelCause(ctx)
+	go func() {
+		select {
+		case <-s.done:
+			ds.abort(status.Convert(ErrServerStopped))
+		case <-ds.ctx.Done():
+		}
+	}()
+	return ds
+}
+
+func (ds *downloadStream) SetHeader(md metadata.MD) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ds.header = metadata.Join(ds.header, md)
+	return nil
+}
+
+// SendHeader fixes header metadata, which is only written along with the
+// first message, since it carries headers of the response as well.
+func (ds *downloadStream) SendHeader(md metadata.MD) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ds.header = metadata.Join(ds.header, md)
+	ds.headerSent = true
+	return nil
+}
+
+func (ds *downloadStream) SetTrailer(md metadata.MD) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.trailer = metadata.Join(ds.trailer, md)
+}
+
+func (ds *downloadStream) Context() context.Context {
+	return ds.ctx
+}
+
+func (ds *downloadStream) SendMsg(m interface{}) error {
+	return ds.counters.sent(ds.sendMsg(m))
+}
+
+func (ds *downloadStream) sendMsg(m interface{}) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.done || ds.ctx.Err() != nil {
+		return toRPCErr(Cause(ds.ctx))
+	}
+	var h http.Header
+	if !ds.written {
+		h = ds.w.Header()
+		writeMetadataToHeader(ds.header, h)
+	}
+	b := ds.desc.Chunk(m, h)
+	if !ds.written {
+		ds.writeHeaderLocked()
+	}
+	if _, err := ds.w.Write(b); err != nil {
+		return toRPCErr(err)
+	}
+	if f, ok := ds.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func (ds *downloadStream) writeHeaderLocked() {
+	ds.written = true
+	ds.headerSent = true
+	if ds.w.Header().Get("Content-Type") == "" {
+		ds.w.Header().Set("Content-Type", defaultDownloadContentType)
+	}
+	ds.w.WriteHeader(http.StatusOK)
+}
+
+func (ds *downloadStream) RecvMsg(m interface{}) error {
+	return ds.counters.received(ds.recvMsg(m))
+}
+
+// recvMsg binds the request from path and query, once.
+func (ds *downloadStream) recvMsg(m interface{}) error {
+	ds.mu.Lock()
+	bound := ds.recvBound
+	ds.recvBound = true
+	ds.mu.Unlock()
+	if bound || ds.desc.Binder == nil {
+		return io.EOF
+	}
+	return ds.desc.Binder(m, ds.r, ds.params)
+}
+
+// abort closes the stream with st while the handler may still be running. A
+// started response is truncated once the handler returns.
+func (ds *downloadStream) abort(st *status.Status) {
+	ds.cancel(st.Err())
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.done {
+		return
+	}
+	ds.done = true
+	if ds.written {
+		ds.truncated = true
+		return
+	}
+	writeStatusResponse(ds.w, httpStatusFromError(st.Err()), st)
+}
+
+func (ds *downloadStream) transport() string {
+	return TransportDownload
+}
+
+func (ds *downloadStream) streamCounters() *messageCounters {
+	return &ds.counters
+}
+
+// finish completes the response, or aborts it if the stream has failed after
+// it has started.
+func (ds *downloadStream) finish(st *status.Status) {
+	ds.cancel(nil)
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.done {
+		if ds.truncated {
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+	ds.done = true
+
+	if !ds.written {
+		if st.Code() != codes.OK {
+			writeStatusResponse(ds.w, httpStatusFromError(st.Err()), st)
+			return
+		}
+		writeMetadataToHeader(ds.header, ds.w.Header())
+		writeMetadataToHeader(ds.trailer, ds.w.Header())
+		ds.writeHeaderLocked()
+		return
+	}
+	if st.Code() != codes.OK {
+		panic(http.ErrAbortHandler)
+	}
+	for k, vv := range ds.trailer {
+		for _, v := range vv {
+			ds.w.Header().Add(http.TrailerPrefix+k, v)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package protoweb
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ContentTypeDuplexJSON is the content type of HTTP/2 streams carrying
+	// protojson messages.
+	ContentTypeDuplexJSON = "application/x-protoweb-stream+json"
+	// ContentTypeDuplexProto is the content type of HTTP/2 streams carrying
+	// binary protobuf messages.
+	ContentTypeDuplexProto = "application/x-protoweb-stream+proto"
+
+	// encodingProto is the encoding of messages marshaled by proto.
+	encodingProto = "proto"
+
+	// duplexPrefixSize is the size of the prefix of every message, a flag
+	// byte followed by the big-endian length.
+	duplexPrefixSize = 5
+	// duplexFlagTrailer marks the last message of a response, which is a
+	// streamFrame carrying trailer and the final status as JSON.
+	duplexFlagTrailer = 0x80
+
+	defaultDuplexMaxMessageSize = 4 << 20
+)
+
+// StreamHTTP2 serves streams over HTTP/2 as well, for clients which can send
+// a request body while reading the response, e.g. Go clients. A stream is
+// opened by a POST to its path with the content type ContentTypeDuplexJSON or
+// ContentTypeDuplexProto, the request of a server-only stream is bound from
+// path and query.
+//
+// Both bodies are sequences of messages, each prefixed with a flag byte and
+// its length as 4 bytes in big endian. The client ends its side by closing
+// the request body. Header metadata is sent as response headers, and the last
+// message of the response, flagged with 0x80, is a JSON object carrying
+// trailer metadata and the final status, in the form of
+// {"trailer": {...}, "status": {...}}.
+//
+// Messages larger than maxMessageSize are rejected with ResourceExhausted,
+// zero means 4MB.
+func StreamHTTP2(maxMessageSize int) ServerOption {
+	if maxMessageSize <= 0 {
+		maxMessageSize = defaultDuplexMaxMessageSize
+	}
+	return func(o *serverOptions) {
+		o.duplexMaxMessageSize = maxMessageSize
+	}
+}
+
+// isDuplexRequest tells whether r opens an HTTP/2 stream.
+func isDuplexRequest(r *http.Request) bool {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return ct == ContentTypeDuplexJSON || ct == ContentTypeDuplexProto
+}
+
+// processStreamPost serves a POST to the path of a stream, which opens either
+// an HTTP/2 stream or a long-polling session.
+func (s *Server) processStreamPost(w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, sd *StreamDesc) {
+	if s.opts.duplexMaxMessageSize > 0 && isDuplexRequest(r) {
+		s.processDuplexRequest(w, r, params, si, sd)
+		return
+	}
+	if s.opts.longPollPath != "" {
+		s.processPollOpen(w, r, params, si, sd)
+		return
+	}
+	writeStatusResponse(w, http.StatusUnsupportedMediaType, status.Newf(codes.InvalidArgument, "unsupported content type %q", r.Header.Get("Content-Type")))
+}
+
+func (s *Server) processDuplexRequest(w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, sd *StreamDesc) {
+	if r.ProtoMajor < 2 {
+		writeStatusResponse(w, http.StatusHTTPVersionNotSupported, status.New(codes.InvalidArgument, "HTTP/2 is required"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeStatusResponse(w, http.StatusInternalServerError, status.New(codes.Internal, "response cannot be flushed"))
+		return
+	}
+	ctx, ok := s.authorizeStream(peer.NewContext(r.Context(), newPeer(r)), w, r, false)
+	if !ok {
+		return
+	}
+
+	ds := newDuplexStream(ctx, s, sd, w, flusher, r, params)
+	go ds.readLoop()
+	err := s.handleStream(si, sd, ds)
+	st, _ := status.FromError(toRPCErr(err))
+	if err := ds.finish(st); err != nil {
+		s.logger.Debugf("stream %q: %s", sd.StreamName, err)
+	}
+}
+
+// duplexStream is a stream over a single HTTP/2 request.
+type duplexStream struct {
+	ctx         context.Context
+	cancel      cancelCauseFunc
+	desc        *StreamDesc
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	r           *http.Request
+	params      httprouter.Params
+	contentType string
+	maxSize     int
+	metrics     *streamMetrics
+	limiter     *rateLimiter
+	queue       *sendQueue
+	recv        *recvBuffer
+	counters    messageCounters
+
+	// sendMu serializes writes to the response, which are made without
+	// holding mu for a stalled client not to block the stream.
+	sendMu     sync.Mutex
+	mu         sync.Mutex
+	header     metadata.MD
+	headerSent bool
+	trailer    metadata.MD
+	recvBound  bool
+	done       bool
+	failed     *status.Status
+}
+
+func newDuplexStream(ctx context.Context, s *Server, sd *StreamDesc, w http.ResponseWriter, flusher http.Flusher, r *http.Request, params httprouter.Params) *duplexStream {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	ds := &duplexStream{
+		desc:        sd,
+		w:           w,
+		flusher:     flusher,
+		r:           r,
+		params:      params,
+		contentType: ct,
+		maxSize:     s.opts.duplexMaxMessageSize,
+		metrics:     &s.metrics,
+		limiter:     newRateLimiter(s.opts.flowControl),
+		queue:       newSendQueue(s.opts.flowControl, &s.metrics),
+		recv:        newRecvBuffer(s.opts.flowControl.inboundQueue()),
+		header:      metadata.MD{},
+		trailer:     metadata.MD{},
+	}
+	if ds.queue != nil {
+		ds.queue.start(ds.writeMessage)
+	}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, &serverStreamTransport{
+		method: sd.StreamName,
+		ss:     ds,
+	})
+	ds.ctx, ds.cancel = withCancelCause(ctx)
+	go func() {
+		select {
+		case <-s.done:
+			ds.abort(status.Convert(ErrServerStopped))
+		case <-ds.ctx.Done():
+		}
+	}()
+	return ds
+}
+
+func (ds *duplexStream) SetHeader(md metadata.MD) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ds.header = metadata.Join(ds.header, md)
+	return nil
+}
+
+func (ds *duplexStream) SendHeader(md metadata.MD) error {
+	ds.sendMu.Lock()
+	defer ds.sendMu.Unlock()
+	ds.mu.Lock()
+	if ds.headerSent {
+		ds.mu.Unlock()
+		return ErrIllegalHeaderWrite
+	}
+	ds.header = metadata.Join(ds.header, md)
+	ds.mu.Unlock()
+	ds.writeHeader()
+	return nil
+}
+
+func (ds *duplexStream) SetTrailer(md metadata.MD) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.trailer = metadata.Join(ds.trailer, md)
+}
+
+func (ds *duplexStream) Context() context.Context {
+	return ds.ctx
+}
+
+func (ds *duplexStream) SendMsg(m interface{}) error {
+	b, err := ds.marshal(m.(proto.Message))
+	if err != nil {
+		return err
+	}
+	return ds.sendPrepared(b)
+}
+
+func (ds *duplexStream) encoding() string {
+	if ds.contentType == ContentTypeDuplexProto {
+		return encodingProto
+	}
+	return encodingJSON
+}
+
+func (ds *duplexStream) marshal(m proto.Message) ([]byte, error) {
+	if ds.contentType == ContentTypeDuplexProto {
+		return proto.Marshal(m)
+	}
+	return protojsonMarshalOptions.Marshal(m)
+}
+
+func (ds *duplexStream) sendPrepared(b []byte) error {
+	return ds.counters.sent(ds.sendBytes(b))
+}
+
+func (ds *duplexStream) sendBytes(b []byte) error {
+	ds.mu.Lock()
+	if ds.done || ds.ctx.Err() != nil {
+		ds.mu.Unlock()
+		return toRPCErr(Cause(ds.ctx))
+	}
+	ds.mu.Unlock()
+	if ds.queue != nil {
+		return ds.queue.push(ds.ctx, b)
+	}
+	return ds.writeMessage(b)
+}
+
+func (ds *duplexStream) writeMessage(b []byte) error {
+	ds.sendMu.Lock()
+	defer ds.sendMu.Unlock()
+	ds.mu.Lock()
+	done := ds.done
+	ds.mu.Unlock()
+	if done {
+		return status.Error(codes.Canceled, "stream is closed")
+	}
+	ds.writeHeader()
+	return ds.write(0, b)
+}
+
+// write writes a prefixed message and flushes it, with sendMu held.
+func (ds *duplexStream) write(flag byte, b []byte) error {
+	var prefix [duplexPrefixSize]byte
+	prefix[0] = flag
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(b)))
+	if _, err := ds.w.Write(prefix[:]); err != nil {
+		return toRPCErr(err)
+	}
+	if _, err := ds.w.Write(b); err != nil {
+		return toRPCErr(err)
+	}
+	ds.flusher.Flush()
+	return nil
+}
+
+// writeHeader writes header as response headers unless they are sent, with
+// sendMu held.
+func (ds *duplexStream) writeHeader() {
+	ds.mu.Lock()
+	if ds.headerSent {
+		ds.mu.Unlock()
+		return
+	}
+	ds.headerSent = true
+	header := ds.header
+	ds.mu.Unlock()
+	writeMetadataToHeader(header, ds.w.Header())
+	ds.w.Header().Set("Content-Type", ds.contentType)
+	ds.w.WriteHeader(http.StatusOK)
+	ds.flusher.Flush()
+}
+
+func (ds *duplexStream) RecvMsg(m interface{}) error {
+	return ds.counters.received(ds.recvMsg(m))
+}
+
+func (ds *duplexStream) recvMsg(m interface{}) error {
+	if !ds.desc.ClientStreams && ds.desc.Binder != nil {
+		// the request of a server-only stream is bound from path and query,
+		// instead of being sent as the first message.
+		ds.mu.Lock()
+		bound := ds.recvBound
+		ds.recvBound = true
+		ds.mu.Unlock()
+		if bound {
+			return io.EOF
+		}
+		return ds.desc.Binder(m, ds.r, ds.params)
+	}
+
+	b, err := ds.recv.get(ds.ctx)
+	if err != nil {
+		return err
+	}
+	if ds.contentType == ContentTypeDuplexProto {
+		err = proto.Unmarshal(b, m.(proto.Message))
+	} else {
+		err = protojsonUnmarshalOptions.Unmarshal(b, m.(proto.Message))
+	}
+	if err != nil {
+		return err
+	}
+	if ds.desc.Binder != nil {
+		return ds.desc.Binder(m, ds.r, ds.params)
+	}
+	return nil
+}
+
+// readLoop reads messages of the request body into recv, until the client
+// closes it. Reading stops while recv is full, so that a client sending
+// faster than the handler receives is held back by HTTP/2 flow control.
+func (ds *duplexStream) readLoop() {
+	var prefix [duplexPrefixSize]byte
+	for {
+		if _, err := io.ReadFull(ds.r.Body, prefix[:]); err != nil {
+			if err == io.EOF {
+				ds.recv.close(io.EOF)
+				return
+			}
+			cause := status.Errorf(codes.Unavailable, "connection lost: %s", err)
+			ds.recv.close(cause)
+			ds.cancel(cause)
+			return
+		}
+		if prefix[0] != 0 {
+			ds.abort(status.Newf(codes.InvalidArgument, "unexpected message flag %#x", prefix[0]))
+			return
+		}
+		n := binary.BigEndian.Uint32(prefix[1:])
+		if int64(n) > int64(ds.maxSize) {
+			ds.abort(status.Newf(codes.ResourceExhausted, "message of %d bytes exceeds the limit of %d bytes", n, ds.maxSize))
+			return
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(ds.r.Body, b); err != nil {
+			cause := status.Errorf(codes.Unavailable, "connection lost: %s", err)
+			ds.recv.close(cause)
+			ds.cancel(cause)
+			return
+		}
+		if ds.limiter != nil && !ds.limiter.allow() {
+			atomic.AddUint64(&ds.metrics.rateLimited, 1)
+			ds.abort(errInboundRateExceeded)
+			return
+		}
+		_ = ds.recv.put(ds.ctx, b)
+	}
+}
+
+// abort cancels the stream while the handler may still be running, which is
+// finished with st instead of the status returned by the handler. The trailer
+// is written once the handler returns, for a stalled client not to block the
+// caller.
+func (ds *duplexStream) abort(st *status.Status) {
+	ds.mu.Lock()
+	if ds.failed == nil {
+		ds.failed = st
+	}
+	ds.mu.Unlock()
+	ds.cancel(st.Err())
+	ds.recv.close(st.Err())
+	if ds.queue != nil {
+		ds.queue.fail(st.Err())
+	}
+}
+
+func (ds *duplexStream) transport() string {
+	return TransportHTTP2
+}
+
+func (ds *duplexStream) streamCounters() *messageCounters {
+	return &ds.counters
+}
+
+// finish sends trailer along with the final status of the stream.
+func (ds *duplexStream) finish(st *status.Status) error {
+	ds.cancel(nil)
+	if ds.queue != nil {
+		ds.queue.close()
+	}
+	ds.sendMu.Lock()
+	defer ds.sendMu.Unlock()
+	ds.mu.Lock()
+	if ds.done {
+		ds.mu.Unlock()
+		return nil
+	}
+	ds.done = true
+	if ds.failed != nil {
+		st = ds.failed
+	}
+	trailer := ds.trailer
+	ds.mu.Unlock()
+
+	ds.writeHeader()
+	frame, err := newTrailerFrame(trailer, st)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	return ds.write(duplexFlagTrailer, b)
+}
@@ -0,0 +1,117 @@
+package protoweb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDuplex(t *testing.T) {
+	_, ts := newDuplexTestServer(t)
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	// the response headers come with the first message
+	go writeDuplexMessage(t, pw, `"a"`)
+	resp := openDuplexStream(t, ts, "/echo", pr)
+	defer resp.Body.Close()
+
+	if flag, b := readDuplexMessage(t, resp.Body); flag != 0 || string(b) != `"a"` {
+		t.Fatalf("message = %#x %q, want \"a\"", flag, b)
+	}
+	_ = pw.Close()
+	if st := readDuplexTrailer(t, resp.Body); st.Code() != codes.OK {
+		t.Errorf("status = %v, want %s", st, codes.OK)
+	}
+}
+
+func TestDuplexCloseStream(t *testing.T) {
+	s, ts := newDuplexTestServer(t)
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	responses := make(chan *http.Response, 1)
+	go func() {
+		responses <- openDuplexStream(t, ts, "/wait", pr)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.Streams()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream is not opened")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := s.CloseStream(s.Streams()[0].ID, status.New(codes.Unavailable, "evicted")); err != nil {
+		t.Fatal(err)
+	}
+	resp := <-responses
+	defer resp.Body.Close()
+	if st := readDuplexTrailer(t, resp.Body); st.Code() != codes.Unavailable || st.Message() != "evicted" {
+		t.Errorf("status = %v, want %s evicted", st, codes.Unavailable)
+	}
+}
+
+func newDuplexTestServer(t *testing.T) (*Server, *httptest.Server) {
+	s := NewServer(StreamHTTP2(0))
+	s.register(&testServiceDesc, nil)
+	ts := httptest.NewUnstartedServer(s)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(func() {
+		s.Stop()
+		ts.Close()
+	})
+	return s, ts
+}
+
+func openDuplexStream(t *testing.T, ts *httptest.Server, path string, body io.Reader) *http.Response {
+	r, _ := http.NewRequest(http.MethodPost, ts.URL+path, body)
+	r.Header.Set("Content-Type", ContentTypeDuplexJSON)
+	resp, err := ts.Client().Do(r)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	return resp
+}
+
+func writeDuplexMessage(t *testing.T, w io.Writer, m string) {
+	var prefix [duplexPrefixSize]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(m)))
+	if _, err := w.Write(append(prefix[:], m...)); err != nil {
+		t.Error(err)
+	}
+}
+
+func readDuplexMessage(t *testing.T, r io.Reader) (byte, []byte) {
+	var prefix [duplexPrefixSize]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	return prefix[0], b
+}
+
+func readDuplexTrailer(t *testing.T, r io.Reader) *status.Status {
+	flag, b := readDuplexMessage(t, r)
+	if flag != duplexFlagTrailer {
+		t.Fatalf("flag = %#x, want trailer", flag)
+	}
+	frame := &streamFrame{}
+	if err := json.Unmarshal(b, frame); err != nil {
+		t.Fatal(err)
+	}
+	return frameStatus(t, frame)
+}
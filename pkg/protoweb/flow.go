@@ -0,0 +1,298 @@
+package protoweb
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamFlowControlParams bounds what a single stream may cost, served over
+// WebSocket, multiplexed, long-polling or HTTP/2. Zero values disable the
+// corresponding limit.
+type StreamFlowControlParams struct {
+	// InboundRate is how many messages per second a client may send on a
+	// stream, the stream is closed with ResourceExhausted once exceeded.
+	InboundRate float64
+	// InboundBurst is how many messages may be sent at once above the rate,
+	// defaults to the rate rounded down, or 1.
+	InboundBurst int
+	// InboundQueue is how many received messages may wait for RecvMsg,
+	// defaults to 32. While it is full, reading stops on WebSocket and HTTP/2
+	// streams, and long-polling sends wait for room. A stream of a
+	// multiplexed connection, which is read for every stream, is closed with
+	// ResourceExhausted instead.
+	InboundQueue int
+	// OutboundQueue is how many messages may wait to be written to a slow
+	// client, SendMsg no longer writes in place when set. For long-polling,
+	// it is how many messages may wait to be polled.
+	OutboundQueue int
+	// Overflow is what to do when the outbound queue is full.
+	Overflow OverflowPolicy
+}
+
+// StreamFlowControl sets inbound rate limits and outbound queues of streams.
+func StreamFlowControl(fc StreamFlowControlParams) ServerOption {
+	if fc.InboundRate > 0 && fc.InboundBurst <= 0 {
+		fc.InboundBurst = int(fc.InboundRate)
+		if fc.InboundBurst < 1 {
+			fc.InboundBurst = 1
+		}
+	}
+	return func(o *serverOptions) {
+		o.flowControl = fc
+	}
+}
+
+const defaultInboundQueue = 32
+
+func (fc StreamFlowControlParams) inboundQueue() int {
+	if fc.InboundQueue <= 0 {
+		return defaultInboundQueue
+	}
+	return fc.InboundQueue
+}
+
+// StreamMetrics counts flow control events of a Server.
+type StreamMetrics struct {
+	// RateLimited counts streams closed for exceeding the inbound rate.
+	RateLimited uint64
+	// Dropped counts outbound messages dropped on full queues.
+	Dropped uint64
+	// Overflowed counts streams closed on full queues.
+	Overflowed uint64
+	// Blocked counts sends blocked on full queues.
+	Blocked uint64
+	// Queued is how many outbound messages are currently queued.
+	Queued int64
+}
+
+// StreamMetrics returns a snapshot of flow control metrics.
+func (s *Server) StreamMetrics() StreamMetrics {
+	return StreamMetrics{
+		RateLimited: atomic.LoadUint64(&s.metrics.rateLimited),
+		Dropped:     atomic.LoadUint64(&s.metrics.dropped),
+		Overflowed:  atomic.LoadUint64(&s.metrics.overflowed),
+		Blocked:     atomic.LoadUint64(&s.metrics.blocked),
+		Queued:      atomic.LoadInt64(&s.metrics.queued),
+	}
+}
+
+type streamMetrics struct {
+	// accessed atomically
+	rateLimited uint64
+	dropped     uint64
+	overflowed  uint64
+	blocked     uint64
+	queued      int64
+}
+
+var (
+	errInboundRateExceeded = status.New(codes.ResourceExhausted, "inbound message rate exceeded")
+	errInboundQueueFull    = status.New(codes.ResourceExhausted, "inbound queue is full")
+)
+
+// rateLimiter is a token bucket.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(fc StreamFlowControlParams) *rateLimiter {
+	if fc.InboundRate <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:   fc.InboundRate,
+		burst:  float64(fc.InboundBurst),
+		tokens: float64(fc.InboundBurst),
+		last:   time.Now(),
+	}
+}
+
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// sendQueue holds outbound messages of a stream, which are written by run.
+type sendQueue struct {
+	max     int
+	policy  OverflowPolicy
+	metrics *streamMetrics
+	ready   chan struct{}
+	space   chan struct{}
+	done    chan struct{}
+
+	mu      sync.Mutex
+	items   [][]byte
+	closed  bool
+	err     error
+	started bool
+}
+
+func newSendQueue(fc StreamFlowControlParams, metrics *streamMetrics) *sendQueue {
+	if fc.OutboundQueue <= 0 {
+		return nil
+	}
+	return &sendQueue{
+		max:     fc.OutboundQueue,
+		policy:  fc.Overflow,
+		metrics: metrics,
+		ready:   make(chan struct{}, 1),
+		space:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (q *sendQueue) push(ctx context.Context, b []byte) error {
+	blocked := false
+	for {
+		q.mu.Lock()
+		if q.err != nil {
+			q.mu.Unlock()
+			return q.err
+		}
+		if len(q.items) < q.max {
+			q.appendLocked(b)
+			q.mu.Unlock()
+			return nil
+		}
+
+		switch q.policy {
+		case OverflowDrop:
+			q.mu.Unlock()
+			atomic.AddUint64(&q.metrics.dropped, 1)
+			return nil
+		case OverflowDropOldest:
+			q.items = q.items[1:]
+			atomic.AddInt64(&q.metrics.queued, -1)
+			atomic.AddUint64(&q.metrics.dropped, 1)
+			q.appendLocked(b)
+			q.mu.Unlock()
+			return nil
+		case OverflowDisconnect:
+			q.failLocked(status.Error(codes.ResourceExhausted, "outbound queue is full"))
+			q.mu.Unlock()
+			atomic.AddUint64(&q.metrics.overflowed, 1)
+			return q.err
+		}
+		q.mu.Unlock()
+
+		if !blocked {
+			blocked = true
+			atomic.AddUint64(&q.metrics.blocked, 1)
+		}
+		select {
+		case <-q.space:
+		case <-ctx.Done():
+			return toRPCErr(ctx.Err())
+		}
+	}
+}
+
+func (q *sendQueue) appendLocked(b []byte) {
+	q.items = append(q.items, b)
+	atomic.AddInt64(&q.metrics.queued, 1)
+	signal(q.ready)
+}
+
+// start runs write for queued messages in a new goroutine, once.
+func (q *sendQueue) start(write func(b []byte) error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+	go q.run(write)
+}
+
+func (q *sendQueue) run(write func(b []byte) error) {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.mu.Unlock()
+			<-q.ready
+			q.mu.Lock()
+		}
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		b := q.items[0]
+		q.items = q.items[1:]
+		q.mu.Unlock()
+		atomic.AddInt64(&q.metrics.queued, -1)
+		signal(q.space)
+
+		if err := write(b); err != nil {
+			q.fail(toRPCErr(err))
+			return
+		}
+	}
+}
+
+// close stops accepting messages, and waits for queued ones to be written.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	started := q.started
+	if !started {
+		q.failLocked(status.Error(codes.Canceled, "stream is closed"))
+	}
+	if !q.closed {
+		q.closed = true
+		if q.err == nil {
+			q.err = status.Error(codes.Canceled, "stream is closed")
+		}
+		signal(q.ready)
+	}
+	q.mu.Unlock()
+	if started {
+		<-q.done
+	}
+}
+
+// fail discards queued messages, and makes further sends return err.
+func (q *sendQueue) fail(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.failLocked(err)
+}
+
+func (q *sendQueue) failLocked(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+	q.closed = true
+	atomic.AddInt64(&q.metrics.queued, -int64(len(q.items)))
+	q.items = nil
+	signal(q.ready)
+	signal(q.space)
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
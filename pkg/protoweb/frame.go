@@ -0,0 +1,60 @@
+package protoweb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Types of frames on a multiplexed connection.
+const (
+	// frameOpen starts stream ID of Method, the optional Payload is the
+	// first message of the stream.
+	frameOpen = "open"
+	// frameMessage carries a message in Payload, in both directions. Seq is
+	// set on messages of resumable streams.
+	frameMessage = "message"
+	// frameEnd tells that the client has finished sending.
+	frameEnd = "end"
+	// frameCancel tells that the client has cancelled the stream.
+	frameCancel = "cancel"
+	// frameHeader carries header metadata of a stream.
+	frameHeader = "header"
+	// frameTrailer carries trailer metadata and the final status of a stream.
+	frameTrailer = "trailer"
+)
+
+// streamFrame is a control frame of a WebSocket stream. Messages are sent as
+// text frames, while control frames are sent as binary frames so that they
+// never collide with the payload of a message.
+//
+// On a multiplexed connection every frame is a streamFrame sent as text, with
+// ID and Type set.
+type streamFrame struct {
+	ID      uint64          `json:"id,omitempty"`
+	Type    string          `json:"type,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Seq     uint64          `json:"seq,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Header  metadata.MD     `json:"header,omitempty"`
+	Trailer metadata.MD     `json:"trailer,omitempty"`
+	Status  json.RawMessage `json:"status,omitempty"`
+}
+
+func newHeaderFrame(md metadata.MD) *streamFrame {
+	return &streamFrame{
+		Header: md,
+	}
+}
+
+func newTrailerFrame(md metadata.MD, st *status.Status) (*streamFrame, error) {
+	b, err := protojsonMarshalOptions.Marshal(st.Proto())
+	if err != nil {
+		return nil, err
+	}
+	return &streamFrame{
+		Trailer: md,
+		Status:  b,
+	}, nil
+}
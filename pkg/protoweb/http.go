@@ -2,6 +2,10 @@ package protoweb
 
 import (
 	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type httpError struct {
@@ -27,3 +31,42 @@ func (e *httpError) Unwrap() error {
 func (e *httpError) HTTPStatus() int {
 	return e.status
 }
+
+// httpStatusFromError returns the HTTP status of err if it has one, or the one
+// mapped from its gRPC code.
+func httpStatusFromError(err error) int {
+	if he, ok := err.(interface {
+		HTTPStatus() int
+	}); ok {
+		return he.HTTPStatus()
+	}
+	return httpStatusFromCode(status.Code(err))
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,242 @@
+package protoweb
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// encodingJSON is the encoding of messages marshaled by protojson.
+const encodingJSON = "json"
+
+const defaultHubBuffer = 64
+
+// preparedStream is a stream which accepts messages marshaled ahead of time,
+// so that a message sent to many streams is marshaled once per encoding.
+type preparedStream interface {
+	encoding() string
+	marshal(m proto.Message) ([]byte, error)
+	sendPrepared(b []byte) error
+}
+
+// OverflowPolicy tells what to do when a subscriber or a client falls behind.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops new messages until the receiver catches up.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowDisconnect ends the subscription or the stream with
+	// ResourceExhausted.
+	OverflowDisconnect
+	// OverflowDropOldest drops the oldest pending message for a new one.
+	OverflowDropOldest
+	// OverflowBlock blocks the sender until there is room. Publishers of a
+	// Hub are never blocked, it drops new messages instead.
+	OverflowBlock
+)
+
+type hubOptions struct {
+	buffer   int
+	overflow OverflowPolicy
+}
+
+// HubOption configures a Hub.
+type HubOption func(*hubOptions)
+
+// HubBuffer sets how many messages are buffered for every subscriber, defaults
+// to 64.
+func HubBuffer(n int) HubOption {
+	return func(o *hubOptions) {
+		o.buffer = n
+	}
+}
+
+// HubOverflow sets what to do when the buffer of a subscriber is full,
+// defaults to OverflowDrop.
+func HubOverflow(p OverflowPolicy) HubOption {
+	return func(o *hubOptions) {
+		o.overflow = p
+	}
+}
+
+// Hub broadcasts messages published to topics to the server streams
+// subscribed to them.
+type Hub struct {
+	opts hubOptions
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	topics map[string]map[*hubSubscriber]struct{}
+}
+
+func NewHub(opts ...HubOption) *Hub {
+	h := &Hub{
+		opts: hubOptions{
+			buffer: defaultHubBuffer,
+		},
+		done:   make(chan struct{}),
+		topics: map[string]map[*hubSubscriber]struct{}{},
+	}
+	for _, o := range opts {
+		o(&h.opts)
+	}
+	return h
+}
+
+// Publish sends m to every subscriber of topic without blocking, m must not be
+// modified afterwards.
+func (h *Hub) Publish(topic string, m proto.Message) {
+	hm := &hubMessage{
+		msg:     m,
+		encoded: map[string][]byte{},
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.ch <- hm:
+			continue
+		default:
+		}
+		switch h.opts.overflow {
+		case OverflowDisconnect:
+			h.removeLocked(sub)
+			close(sub.overflowed)
+		case OverflowDropOldest:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- hm:
+			default:
+			}
+		}
+	}
+}
+
+// Subscribe sends messages published to topics to ss, until the stream is
+// done, the subscriber is disconnected for falling behind, or the hub is
+// closed. It is meant to be returned from a server-streaming handler.
+//
+// Messages are written to the underlying stream directly, bypassing SendMsg
+// of wrappers set by interceptors.
+func (h *Hub) Subscribe(ss grpc.ServerStream, topics ...string) error {
+	sub := &hubSubscriber{
+		topics:     topics,
+		ch:         make(chan *hubMessage, h.opts.buffer),
+		overflowed: make(chan struct{}),
+	}
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	for _, topic := range topics {
+		subs := h.topics[topic]
+		if subs == nil {
+			subs = map[*hubSubscriber]struct{}{}
+			h.topics[topic] = subs
+		}
+		subs[sub] = struct{}{}
+	}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.removeLocked(sub)
+		h.mu.Unlock()
+	}()
+
+	// header is sent right away, for the client to be connected before any
+	// message is published.
+	if err := ss.SendHeader(nil); err != nil && err != ErrIllegalHeaderWrite {
+		return err
+	}
+	ctx := ss.Context()
+	for {
+		select {
+		case hm := <-sub.ch:
+			if err := hm.send(ss); err != nil {
+				return err
+			}
+		case <-sub.overflowed:
+			return status.Error(codes.ResourceExhausted, "subscriber is too slow")
+		case <-ctx.Done():
+			return toRPCErr(ctx.Err())
+		case <-h.done:
+			return nil
+		}
+	}
+}
+
+// Close ends every subscription.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+}
+
+func (h *Hub) removeLocked(sub *hubSubscriber) {
+	for _, topic := range sub.topics {
+		subs := h.topics[topic]
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+}
+
+type hubSubscriber struct {
+	topics     []string
+	ch         chan *hubMessage
+	overflowed chan struct{}
+}
+
+// hubMessage is a published message, marshaled on first use for every
+// encoding.
+type hubMessage struct {
+	msg proto.Message
+
+	mu      sync.Mutex
+	encoded map[string][]byte
+}
+
+func (hm *hubMessage) send(ss grpc.ServerStream) error {
+	ps, ok := preparedStreamOf(ss)
+	if !ok {
+		return ss.SendMsg(hm.msg)
+	}
+	hm.mu.Lock()
+	b, ok := hm.encoded[ps.encoding()]
+	if !ok {
+		var err error
+		if b, err = ps.marshal(hm.msg); err != nil {
+			hm.mu.Unlock()
+			return err
+		}
+		hm.encoded[ps.encoding()] = b
+	}
+	hm.mu.Unlock()
+	return ps.sendPrepared(b)
+}
+
+// preparedStreamOf finds the stream of this package beneath ss, which is
+// usually wrapped by generated code.
+func preparedStreamOf(ss grpc.ServerStream) (preparedStream, bool) {
+	if ps, ok := ss.(preparedStream); ok {
+		return ps, true
+	}
+	if t, ok := grpc.ServerTransportStreamFromContext(ss.Context()).(*serverStreamTransport); ok {
+		ps, ok := t.ss.(preparedStream)
+		return ps, ok
+	}
+	return nil, false
+}
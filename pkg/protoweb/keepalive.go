@@ -0,0 +1,102 @@
+package protoweb
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamKeepaliveParams detects dead and idle WebSocket streams. A zero value
+// of any field disables the corresponding check.
+type StreamKeepaliveParams struct {
+	// PingInterval is how often a ping is sent to the client.
+	PingInterval time.Duration
+	// PongTimeout is how long to wait for a pong after a ping, before the
+	// client is considered dead.
+	PongTimeout time.Duration
+	// MaxIdle is how long a stream may go without sending or receiving a
+	// message.
+	MaxIdle time.Duration
+	// MaxConnectionAge is how long a stream may live since it was upgraded.
+	MaxConnectionAge time.Duration
+	// WriteTimeout is the deadline of every write to the connection.
+	WriteTimeout time.Duration
+}
+
+func (kp StreamKeepaliveParams) enabled() bool {
+	return kp.PingInterval > 0 || kp.MaxIdle > 0 || kp.MaxConnectionAge > 0
+}
+
+// keepaliveConn is a WebSocket connection checked by runKeepalive.
+type keepaliveConn interface {
+	done() <-chan struct{}
+	writePing() error
+	lastActive() time.Time
+	lastPong() time.Time
+	abort(st *status.Status)
+}
+
+func runKeepalive(c keepaliveConn, kp StreamKeepaliveParams) {
+	var pingC, pongC, idleC, ageC <-chan time.Time
+	if kp.PingInterval > 0 {
+		t := time.NewTicker(kp.PingInterval)
+		defer t.Stop()
+		pingC = t.C
+	}
+	var pongTimer *time.Timer
+	if kp.PingInterval > 0 && kp.PongTimeout > 0 {
+		pongTimer = time.NewTimer(kp.PongTimeout)
+		pongTimer.Stop()
+		defer pongTimer.Stop()
+	}
+	var idleTimer *time.Timer
+	if kp.MaxIdle > 0 {
+		idleTimer = time.NewTimer(kp.MaxIdle)
+		defer idleTimer.Stop()
+		idleC = idleTimer.C
+	}
+	if kp.MaxConnectionAge > 0 {
+		t := time.NewTimer(kp.MaxConnectionAge)
+		defer t.Stop()
+		ageC = t.C
+	}
+
+	var pingAt time.Time
+	for {
+		select {
+		case <-c.done():
+			return
+		case <-pingC:
+			if pongC != nil {
+				// still waiting for pong of the previous ping
+				continue
+			}
+			pingAt = time.Now()
+			if err := c.writePing(); err != nil {
+				c.abort(status.New(codes.Unavailable, err.Error()))
+				return
+			}
+			if pongTimer != nil {
+				pongTimer.Reset(kp.PongTimeout)
+				pongC = pongTimer.C
+			}
+		case <-pongC:
+			pongC = nil
+			if c.lastPong().Before(pingAt) {
+				c.abort(status.New(codes.Unavailable, "keepalive ping timeout"))
+				return
+			}
+		case <-idleC:
+			if idle := time.Since(c.lastActive()); idle < kp.MaxIdle {
+				idleTimer.Reset(kp.MaxIdle - idle)
+				continue
+			}
+			c.abort(status.New(codes.Unavailable, "max idle time exceeded"))
+			return
+		case <-ageC:
+			c.abort(status.New(codes.Unavailable, "max connection age exceeded"))
+			return
+		}
+	}
+}
@@ -0,0 +1,554 @@
+package protoweb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultPollTimeout    = 30 * time.Second
+	defaultSessionTimeout = time.Minute
+)
+
+var errSessionExpired = status.Error(codes.Unavailable, "session has expired")
+
+// LongPollParams controls long-polling sessions. Zero values use defaults.
+type LongPollParams struct {
+	// PollTimeout is how long a poll waits for frames before it returns
+	// empty, defaults to 30 seconds.
+	PollTimeout time.Duration
+	// SessionTimeout is how long a session is kept without being polled,
+	// defaults to 1 minute.
+	SessionTimeout time.Duration
+}
+
+// StreamLongPolling serves streams by long-polling, for clients unable to use
+// WebSocket. A session is opened by a POST to the path of the stream, with the
+// first message as the body, or with the request bound from path and query
+// for server-only streams with a Binder, and replied with
+// {"session": "<id>"}.
+//
+// A GET to path/:session polls pending header, message and trailer frames as
+// a JSON array, which is empty if none arrived within the poll timeout. A POST
+// to it sends a message, end or cancel frame, and a DELETE cancels the stream.
+// Every request of a session is authorized as the opening one, and rejected
+// unless it is of the same principal.
+func StreamLongPolling(path string, lp LongPollParams) ServerOption {
+	if lp.PollTimeout <= 0 {
+		lp.PollTimeout = defaultPollTimeout
+	}
+	if lp.SessionTimeout <= 0 {
+		lp.SessionTimeout = defaultSessionTimeout
+	}
+	return func(o *serverOptions) {
+		o.longPollPath = path
+		o.longPoll = lp
+	}
+}
+
+func (s *Server) processPollOpen(w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, sd *StreamDesc) {
+	ctx, ok := s.authorizeStream(peer.NewContext(r.Context(), newPeer(r)), w, r, false)
+	if !ok {
+		return
+	}
+	body, ok := s.readPollBody(w, r)
+	if !ok {
+		return
+	}
+	id, err := newSessionToken()
+	if err != nil {
+		writeStatusResponse(w, http.StatusInternalServerError, status.New(codes.Internal, err.Error()))
+		return
+	}
+
+	ps := newPollSession(ctx, s, id, sd, r, params)
+	if (sd.ClientStreams || sd.Binder == nil) && len(body) > 0 {
+		_ = ps.recv.put(ps.ctx, body)
+	}
+	s.polls.add(ps)
+	go func() {
+		err := s.handleStream(si, sd, ps)
+		st, _ := status.FromError(toRPCErr(err))
+		ps.finish(st)
+	}()
+
+	b, _ := json.Marshal(map[string]string{"session": id})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(b)
+}
+
+func (s *Server) processPollRequest(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	// not rejected once the server is stopped, for the trailer to be polled
+	ctx, ok := s.authenticateStream(peer.NewContext(r.Context(), newPeer(r)), w, r)
+	if !ok {
+		return
+	}
+	ps, ok := s.polls.get(params.ByName("session"))
+	if !ok {
+		writeStatusResponse(w, http.StatusNotFound, status.New(codes.NotFound, "session is not found or has expired"))
+		return
+	}
+	if principal, _ := PrincipalFromContext(ctx); principal != ps.principal {
+		writeStatusResponse(w, http.StatusForbidden, status.New(codes.PermissionDenied, "session is opened by another principal"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		frames := ps.poll(r.Context())
+		b, err := json.Marshal(frames)
+		if err != nil {
+			writeStatusResponse(w, http.StatusInternalServerError, status.New(codes.Internal, err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	case http.MethodPost:
+		body, ok := s.readPollBody(w, r)
+		if !ok {
+			return
+		}
+		frame := &streamFrame{}
+		if err := json.Unmarshal(body, frame); err != nil {
+			writeStatusResponse(w, http.StatusBadRequest, status.Newf(codes.InvalidArgument, "malformed frame: %s", err))
+			return
+		}
+		switch frame.Type {
+		case frameMessage:
+			if ps.limiter != nil && !ps.limiter.allow() {
+				atomic.AddUint64(&s.metrics.rateLimited, 1)
+				ps.abort(errInboundRateExceeded)
+				writeStatusResponse(w, http.StatusTooManyRequests, errInboundRateExceeded)
+				return
+			}
+			if err := ps.put(r.Context(), frame.Payload); err != nil {
+				writeStatusResponse(w, httpStatusFromError(err), status.Convert(err))
+				return
+			}
+		case frameEnd:
+			ps.recv.close(io.EOF)
+		case frameCancel:
+			ps.cancel(errStreamCancelled)
+		default:
+			writeStatusResponse(w, http.StatusBadRequest, status.Newf(codes.InvalidArgument, "unexpected frame %q", frame.Type))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		ps.cancel(errStreamCancelled)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// readPollBody reads the body of a long-polling request, of up to the max
+// size of received messages. The request is rejected if it returns false.
+func (s *Server) readPollBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	max := s.opts.maxRecvMsgSize
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+	if err != nil {
+		writeStatusResponse(w, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return nil, false
+	}
+	if len(body) > max {
+		writeStatusResponse(w, http.StatusRequestEntityTooLarge, status.Newf(codes.ResourceExhausted, "received message larger than max (%d bytes)", max))
+		return nil, false
+	}
+	return body, true
+}
+
+func writeStatusResponse(w http.ResponseWriter, httpStatus int, st *status.Status) {
+	b, _ := protojsonMarshalOptions.Marshal(st.Proto())
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(b)
+}
+
+// pollRegistry keeps long-polling sessions by their ids.
+type pollRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*pollSession
+}
+
+func (pr *pollRegistry) add(ps *pollSession) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.sessions == nil {
+		pr.sessions = map[string]*pollSession{}
+	}
+	pr.sessions[ps.id] = ps
+}
+
+func (pr *pollRegistry) get(id string) (*pollSession, bool) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	ps, ok := pr.sessions[id]
+	return ps, ok
+}
+
+func (pr *pollRegistry) remove(id string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	delete(pr.sessions, id)
+}
+
+// pollSession is a stream served by long-polling. Frames are queued until the
+// client polls them, up to the outbound queue of flow control for messages.
+type pollSession struct {
+	id        string
+	principal string
+	s         *Server
+	desc      *StreamDesc
+	r         *http.Request
+	params    httprouter.Params
+	lp        LongPollParams
+	fc        StreamFlowControlParams
+	limiter   *rateLimiter
+	ctx       context.Context
+	cancel    cancelCauseFunc
+	recv      *recvBuffer
+	notify    chan struct{}
+	space     chan struct{}
+	counters  messageCounters
+
+	mu         sync.Mutex
+	header     metadata.MD
+	headerSent bool
+	trailer    metadata.MD
+	recvBound  bool
+	pending    []*streamFrame
+	finished   bool
+	polling    int
+	timer      *time.Timer
+	failed     *status.Status
+}
+
+func newPollSession(ctx context.Context, s *Server, id string, sd *StreamDesc, r *http.Request, params httprouter.Params) *pollSession {
+	principal, _ := PrincipalFromContext(ctx)
+	ps := &pollSession{
+		id:        id,
+		principal: principal,
+		s:         s,
+		desc:      sd,
+		r:         r,
+		params:    params,
+		lp:        s.opts.longPoll,
+		fc:        s.opts.flowControl,
+		limiter:   newRateLimiter(s.opts.flowControl),
+		recv:      newRecvBuffer(s.opts.flowControl.inboundQueue()),
+		notify:    make(chan struct{}, 1),
+		space:     make(chan struct{}, 1),
+		header:    metadata.MD{},
+		trailer:   metadata.MD{},
+	}
+	// the stream outlives the request it was opened with
+	ctx = grpc.NewContextWithServerTransportStream(detachContext(ctx), &serverStreamTransport{
+		method: sd.StreamName,
+		ss:     ps,
+	})
+	ps.ctx, ps.cancel = withCancelCause(ctx)
+	go func() {
+		select {
+		case <-s.done:
+			// the session is kept for the trailer to be polled
+			ps.cancel(ErrServerStopped)
+		case <-ps.ctx.Done():
+		}
+	}()
+	ps.timer = time.AfterFunc(ps.lp.SessionTimeout, ps.expire)
+	return ps
+}
+
+func (ps *pollSession) SetHeader(md metadata.MD) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ps.header = metadata.Join(ps.header, md)
+	return nil
+}
+
+func (ps *pollSession) SendHeader(md metadata.MD) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ps.header = metadata.Join(ps.header, md)
+	ps.writeHeaderLocked()
+	return nil
+}
+
+func (ps *pollSession) SetTrailer(md metadata.MD) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.trailer = metadata.Join(ps.trailer, md)
+}
+
+func (ps *pollSession) Context() context.Context {
+	return ps.ctx
+}
+
+func (ps *pollSession) SendMsg(m interface{}) error {
+	b, err := ps.marshal(m.(proto.Message))
+	if err != nil {
+		return err
+	}
+	return ps.sendPrepared(b)
+}
+
+func (ps *pollSession) encoding() string {
+	return encodingJSON
+}
+
+func (ps *pollSession) marshal(m proto.Message) ([]byte, error) {
+	return protojsonMarshalOptions.Marshal(m)
+}
+
+func (ps *pollSession) sendPrepared(b []byte) error {
+	return ps.counters.sent(ps.sendBytes(b))
+}
+
+func (ps *pollSession) sendBytes(b []byte) error {
+	frame := &streamFrame{
+		Type:    frameMessage,
+		Payload: b,
+	}
+	metrics := &ps.s.metrics
+	blocked := false
+	for {
+		ps.mu.Lock()
+		if err := ps.ctx.Err(); err != nil {
+			ps.mu.Unlock()
+			return toRPCErr(Cause(ps.ctx))
+		}
+		ps.writeHeaderLocked()
+		oldest, n := ps.pendingMessagesLocked()
+		if ps.fc.OutboundQueue <= 0 || n < ps.fc.OutboundQueue {
+			ps.queueLocked(frame)
+			ps.mu.Unlock()
+			return nil
+		}
+
+		switch ps.fc.Overflow {
+		case OverflowDrop:
+			ps.mu.Unlock()
+			atomic.AddUint64(&metrics.dropped, 1)
+			return nil
+		case OverflowDropOldest:
+			ps.pending = append(ps.pending[:oldest], ps.pending[oldest+1:]...)
+			ps.queueLocked(frame)
+			ps.mu.Unlock()
+			atomic.AddUint64(&metrics.dropped, 1)
+			return nil
+		case OverflowDisconnect:
+			ps.mu.Unlock()
+			atomic.AddUint64(&metrics.overflowed, 1)
+			st := status.New(codes.ResourceExhausted, "outbound queue is full")
+			ps.abort(st)
+			return st.Err()
+		}
+		ps.mu.Unlock()
+
+		if !blocked {
+			blocked = true
+			atomic.AddUint64(&metrics.blocked, 1)
+		}
+		select {
+		case <-ps.space:
+		case <-ps.ctx.Done():
+			return toRPCErr(Cause(ps.ctx))
+		}
+	}
+}
+
+// pendingMessagesLocked returns the index of the oldest pending message frame
+// and how many there are.
+func (ps *pollSession) pendingMessagesLocked() (int, int) {
+	oldest, n := -1, 0
+	for i, frame := range ps.pending {
+		if frame.Type == frameMessage {
+			if oldest < 0 {
+				oldest = i
+			}
+			n++
+		}
+	}
+	return oldest, n
+}
+
+// put queues a message sent by the client, waiting while the inbound queue is
+// full until either the request or the session is done.
+func (ps *pollSession) put(ctx context.Context, m []byte) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ps.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ps.recv.put(ctx, m)
+}
+
+func (ps *pollSession) RecvMsg(m interface{}) error {
+	return ps.counters.received(ps.recvMsg(m))
+}
+
+func (ps *pollSession) recvMsg(m interface{}) error {
+	if !ps.desc.ClientStreams && ps.desc.Binder != nil {
+		// the request of a server-only stream is bound from the opening
+		// request.
+		ps.mu.Lock()
+		bound := ps.recvBound
+		ps.recvBound = true
+		ps.mu.Unlock()
+		if bound {
+			return io.EOF
+		}
+		return ps.desc.Binder(m, ps.r, ps.params)
+	}
+
+	b, err := ps.recv.get(ps.ctx)
+	if err != nil {
+		return err
+	}
+	if err := protojsonUnmarshalOptions.Unmarshal(b, m.(proto.Message)); err != nil {
+		return err
+	}
+	if ps.desc.Binder != nil {
+		return ps.desc.Binder(m, ps.r, ps.params)
+	}
+	return nil
+}
+
+// abort cancels the session, which is finished with st instead of the status
+// returned by the handler.
+func (ps *pollSession) abort(st *status.Status) {
+	ps.mu.Lock()
+	if ps.failed == nil {
+		ps.failed = st
+	}
+	ps.mu.Unlock()
+	ps.cancel(st.Err())
+}
+
+func (ps *pollSession) transport() string {
+	return TransportLongPoll
+}
+
+func (ps *pollSession) streamCounters() *messageCounters {
+	return &ps.counters
+}
+
+// finish queues trailer along with the final status of the stream, the
+// session is removed once they are polled.
+func (ps *pollSession) finish(st *status.Status) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.failed != nil {
+		st = ps.failed
+	}
+	ps.writeHeaderLocked()
+	frame, err := newTrailerFrame(ps.trailer, st)
+	if err != nil {
+		ps.s.logger.Debugf("stream %q: %s", ps.desc.StreamName, err)
+		frame, _ = newTrailerFrame(nil, status.New(codes.Internal, err.Error()))
+	}
+	frame.Type = frameTrailer
+	ps.cancel(nil)
+	ps.finished = true
+	ps.queueLocked(frame)
+}
+
+// poll takes pending frames, waiting for up to the poll timeout if there is
+// none.
+func (ps *pollSession) poll(ctx context.Context) []*streamFrame {
+	ps.mu.Lock()
+	ps.polling++
+	ps.mu.Unlock()
+
+	t := time.NewTimer(ps.lp.PollTimeout)
+	defer t.Stop()
+	for {
+		ps.mu.Lock()
+		if len(ps.pending) > 0 {
+			frames := ps.pending
+			ps.pending = nil
+			signal(ps.space)
+			ps.polledLocked()
+			if ps.finished {
+				ps.removeLocked(nil)
+			}
+			ps.mu.Unlock()
+			return frames
+		}
+		ps.mu.Unlock()
+
+		select {
+		case <-ps.notify:
+			continue
+		case <-t.C:
+		case <-ctx.Done():
+		}
+		ps.mu.Lock()
+		ps.polledLocked()
+		ps.mu.Unlock()
+		return []*streamFrame{}
+	}
+}
+
+// polledLocked restarts the session timeout once a poll returns.
+func (ps *pollSession) polledLocked() {
+	ps.polling--
+	ps.timer.Reset(ps.lp.SessionTimeout)
+}
+
+func (ps *pollSession) expire() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.polling > 0 {
+		return
+	}
+	ps.removeLocked(errSessionExpired)
+}
+
+func (ps *pollSession) removeLocked(cause error) {
+	ps.timer.Stop()
+	ps.s.polls.remove(ps.id)
+	ps.cancel(cause)
+}
+
+func (ps *pollSession) writeHeaderLocked() {
+	if ps.headerSent {
+		return
+	}
+	ps.headerSent = true
+	if len(ps.header) == 0 {
+		return
+	}
+	frame := newHeaderFrame(ps.header)
+	frame.Type = frameHeader
+	ps.queueLocked(frame)
+}
+
+func (ps *pollSession) queueLocked(frame *streamFrame) {
+	ps.pending = append(ps.pending, frame)
+	signal(ps.notify)
+}
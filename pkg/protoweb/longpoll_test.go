@@ -0,0 +1,122 @@
+package protoweb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestLongPollRequestMessage(t *testing.T) {
+	_, ts := newTestServer(t, StreamLongPolling("/poll", LongPollParams{PollTimeout: 100 * time.Millisecond}))
+	id := openPollSession(t, ts, "/repeat", `"a"`, nil)
+
+	var messages []string
+	for closed := false; !closed; {
+		for _, frame := range pollFrames(t, ts, id, nil) {
+			switch frame.Type {
+			case frameMessage:
+				messages = append(messages, string(frame.Payload))
+			case frameTrailer:
+				if code := frameStatus(t, frame).Code(); code != codes.OK {
+					t.Fatalf("stream is closed with %s", code)
+				}
+				closed = true
+			}
+		}
+	}
+	if len(messages) != 3 || messages[0] != `"a"` {
+		t.Errorf("messages = %q, want 3 of \"a\"", messages)
+	}
+}
+
+func TestLongPollPrincipal(t *testing.T) {
+	_, ts := newTestServer(t, StreamLongPolling("/poll", LongPollParams{PollTimeout: 100 * time.Millisecond}), StreamAuth(func(ctx context.Context, r *http.Request, token string) (context.Context, error) {
+		return WithPrincipal(ctx, "user-"+token), nil
+	}))
+	id := openPollSession(t, ts, "/watch", `"a"`, http.Header{"Authorization": {"Bearer 1"}})
+
+	r, _ := http.NewRequest(http.MethodGet, ts.URL+"/poll/"+id, nil)
+	r.Header.Set("Authorization", "Bearer 2")
+	resp, err := ts.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+
+	for {
+		frames := pollFrames(t, ts, id, http.Header{"Authorization": {"Bearer 1"}})
+		if len(frames) > 0 {
+			if frames[0].Type != frameMessage || string(frames[0].Payload) != `"a"` {
+				t.Fatalf("frame = %+v, want message", frames[0])
+			}
+			break
+		}
+	}
+}
+
+func TestLongPollInboundQueueFull(t *testing.T) {
+	_, ts := newTestServer(t, StreamLongPolling("/poll", LongPollParams{}), StreamFlowControl(StreamFlowControlParams{InboundQueue: 1}))
+	id := openPollSession(t, ts, "/wait", `"a"`, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodPost, ts.URL+"/poll/"+id, strings.NewReader(`{"type": "message", "payload": "b"}`))
+	resp, err := ts.Client().Do(r)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatalf("status = %d, want the message to wait for room", resp.StatusCode)
+	}
+}
+
+// openPollSession opens a long-polling session of path with body, and returns
+// its id.
+func openPollSession(t *testing.T, ts *httptest.Server, path, body string, header http.Header) string {
+	r, _ := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(body))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	resp, err := ts.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var session struct {
+		Session string `json:"session"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		t.Fatal(err)
+	}
+	return session.Session
+}
+
+func pollFrames(t *testing.T, ts *httptest.Server, id string, header http.Header) []*streamFrame {
+	r, _ := http.NewRequest(http.MethodGet, ts.URL+"/poll/"+id, nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	resp, err := ts.Client().Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var frames []*streamFrame
+	if err := json.NewDecoder(resp.Body).Decode(&frames); err != nil {
+		t.Fatal(err)
+	}
+	return frames
+}
@@ -0,0 +1,518 @@
+package protoweb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamMultiplex serves every stream over a single WebSocket connection at
+// path, in addition to their own paths. Streams are opened by method name in
+// the form of "/package.Service/Method".
+func StreamMultiplex(path string) ServerOption {
+	return func(o *serverOptions) {
+		o.multiplexPath = path
+	}
+}
+
+// MaxConcurrentStreams limits how many streams may be open at once on a
+// multiplexed connection, defaults to 100. Opening more fails with
+// ResourceExhausted.
+func MaxConcurrentStreams(n uint32) ServerOption {
+	if n == 0 {
+		n = defaultMaxConcurrentStreams
+	}
+	return func(o *serverOptions) {
+		o.maxConcurrentStreams = n
+	}
+}
+
+const defaultMaxConcurrentStreams = 100
+
+func (s *Server) processMultiplexRequest(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		st := status.New(codes.InvalidArgument, "websocket upgrade required")
+		b, _ := protojsonMarshalOptions.Marshal(st.Proto())
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(b)
+		return
+	}
+	ctx, ok := s.authorizeStream(peer.NewContext(r.Context(), newPeer(r)), w, r, true)
+	if !ok {
+		return
+	}
+
+	upgrader := *s.upgrader
+	upgrader.Protocol = s.opts.selectProtocol(r)
+	var flateParams *wsflate.Parameters
+	if s.opts.compression != nil {
+		upgrader.Negotiate = s.opts.compression.negotiate(&flateParams)
+	}
+	conn, _, _, err := upgrader.Upgrade(r, w)
+	if err != nil {
+		s.logger.Debugf("multiplex: %s", err)
+		return
+	}
+
+	ctx, cancel := withCancelCause(ctx)
+	mc := &muxConn{
+		s:         s,
+		ctx:       ctx,
+		cancel:    cancel,
+		conn:      conn,
+		flate:     newDeflater(s.opts.compression, flateParams),
+		keepalive: s.opts.streamKeepalive,
+		streams:   map[uint64]*muxStream{},
+	}
+	go func() {
+		select {
+		case <-s.done:
+			mc.abort(status.Convert(ErrServerStopped))
+		case <-ctx.Done():
+		}
+	}()
+	mc.serve()
+}
+
+// muxConn is a WebSocket connection carrying many streams.
+type muxConn struct {
+	// accessed atomically, unix nanoseconds
+	lastActiveAt int64
+	lastPongAt   int64
+
+	s         *Server
+	ctx       context.Context
+	cancel    cancelCauseFunc
+	conn      net.Conn
+	flate     *deflater
+	keepalive StreamKeepaliveParams
+	wg        sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	streams map[uint64]*muxStream
+}
+
+func (mc *muxConn) serve() {
+	mc.touch()
+	if mc.keepalive.enabled() {
+		go runKeepalive(mc, mc.keepalive)
+	}
+
+	err := readMessages(mc.conn, writerFunc(mc.writeControl), mc.flate, mc.s.opts.maxRecvMsgSize, func() {
+		atomic.StoreInt64(&mc.lastPongAt, time.Now().UnixNano())
+	}, func(b []byte) {
+		mc.touch()
+		frame := &streamFrame{}
+		if err := json.Unmarshal(b, frame); err != nil {
+			mc.s.logger.Debugf("multiplex: malformed frame: %s", err)
+			return
+		}
+		mc.handleFrame(frame)
+	})
+	if _, ok := err.(wsutil.ClosedError); ok {
+		// close frame has been replied by the control handler
+		mc.mu.Lock()
+		mc.closed = true
+		mc.mu.Unlock()
+	} else if st, ok := status.FromError(err); ok {
+		mc.abort(st)
+	}
+
+	mc.cancel(connCause(err))
+	mc.wg.Wait()
+	_ = mc.conn.Close()
+}
+
+func (mc *muxConn) handleFrame(frame *streamFrame) {
+	if frame.Type == frameOpen {
+		mc.open(frame)
+		return
+	}
+
+	mc.mu.Lock()
+	ms := mc.streams[frame.ID]
+	mc.mu.Unlock()
+	if ms == nil {
+		return
+	}
+	switch frame.Type {
+	case frameMessage:
+		if ms.limiter != nil && !ms.limiter.allow() {
+			atomic.AddUint64(&mc.s.metrics.rateLimited, 1)
+			ms.fail(errInboundRateExceeded)
+			return
+		}
+		if !ms.recv.tryPut(frame.Payload) {
+			// other streams are not to wait for this one
+			atomic.AddUint64(&mc.s.metrics.overflowed, 1)
+			ms.fail(errInboundQueueFull)
+		}
+	case frameEnd:
+		ms.recv.close(io.EOF)
+	case frameCancel:
+		ms.mu.Lock()
+		ms.canceled = true
+		ms.mu.Unlock()
+		ms.cancel(errStreamCancelled)
+		if ms.queue != nil {
+			ms.queue.fail(errStreamCancelled)
+		}
+	}
+}
+
+func (mc *muxConn) open(frame *streamFrame) {
+	if frame.ID == 0 {
+		// no reply could be told apart from other frames
+		mc.abort(status.New(codes.InvalidArgument, "stream id 0 is reserved"))
+		return
+	}
+	si, sd, ok := mc.s.lookupStream(frame.Method)
+	if !ok {
+		mc.writeStatus(frame.ID, status.Newf(codes.Unimplemented, "unknown method %s", frame.Method))
+		return
+	}
+
+	mc.mu.Lock()
+	if _, ok := mc.streams[frame.ID]; ok {
+		mc.mu.Unlock()
+		mc.writeStatus(frame.ID, status.Newf(codes.FailedPrecondition, "stream %d is already open", frame.ID))
+		return
+	}
+	if max := mc.s.opts.maxConcurrentStreams; uint32(len(mc.streams)) >= max {
+		mc.mu.Unlock()
+		mc.writeStatus(frame.ID, status.Newf(codes.ResourceExhausted, "max concurrent streams (%d) exceeded", max))
+		return
+	}
+	ms := newMuxStream(mc, frame.ID, sd)
+	mc.streams[frame.ID] = ms
+	mc.mu.Unlock()
+
+	if len(frame.Payload) > 0 {
+		ms.recv.tryPut(frame.Payload)
+	}
+
+	mc.wg.Add(1)
+	go func() {
+		defer mc.wg.Done()
+		err := mc.s.handleStream(si, sd, ms)
+		mc.mu.Lock()
+		delete(mc.streams, frame.ID)
+		mc.mu.Unlock()
+		st, _ := status.FromError(toRPCErr(err))
+		if err := ms.finish(st); err != nil {
+			mc.s.logger.Debugf("multiplex: stream %q: %s", sd.StreamName, err)
+		}
+	}()
+}
+
+func (mc *muxConn) writeStatus(id uint64, st *status.Status) {
+	frame, err := newTrailerFrame(nil, st)
+	if err != nil {
+		return
+	}
+	frame.ID = id
+	frame.Type = frameTrailer
+	_ = mc.writeFrame(frame)
+}
+
+func (mc *muxConn) writeFrame(frame *streamFrame) error {
+	b, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.closed {
+		return status.Error(codes.Unavailable, "connection is closed")
+	}
+	return mc.writeLocked(ws.OpText, b)
+}
+
+func (mc *muxConn) writeLocked(op ws.OpCode, b []byte) error {
+	if t := mc.keepalive.WriteTimeout; t > 0 {
+		_ = mc.conn.SetWriteDeadline(time.Now().Add(t))
+	}
+	return writeMessage(mc.conn, op, b, mc.flate)
+}
+
+// writeControl writes replies to control frames, serialized with other writes.
+func (mc *muxConn) writeControl(p []byte) (int, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.closed {
+		return len(p), nil
+	}
+	if t := mc.keepalive.WriteTimeout; t > 0 {
+		_ = mc.conn.SetWriteDeadline(time.Now().Add(t))
+	}
+	return mc.conn.Write(p)
+}
+
+// abort closes every stream on the connection with given status, and the
+// connection afterwards.
+func (mc *muxConn) abort(st *status.Status) {
+	mc.cancel(st.Err())
+	_ = mc.conn.SetWriteDeadline(time.Now().Add(closeTimeout))
+	b, _ := protojsonMarshalOptions.Marshal(st.Proto())
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.closed {
+		return
+	}
+	mc.closed = true
+	defer mc.conn.Close()
+	for id := range mc.streams {
+		frame, _ := json.Marshal(&streamFrame{
+			ID:     id,
+			Type:   frameTrailer,
+			Status: b,
+		})
+		if err := mc.writeLocked(ws.OpText, frame); err != nil {
+			return
+		}
+	}
+	_ = mc.writeLocked(ws.OpClose, ws.NewCloseFrameBody(ws.StatusNormalClosure, ""))
+}
+
+func (mc *muxConn) done() <-chan struct{} {
+	return mc.ctx.Done()
+}
+
+func (mc *muxConn) writePing() error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.closed {
+		return nil
+	}
+	return mc.writeLocked(ws.OpPing, nil)
+}
+
+func (mc *muxConn) touch() {
+	atomic.StoreInt64(&mc.lastActiveAt, time.Now().UnixNano())
+}
+
+func (mc *muxConn) lastActive() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&mc.lastActiveAt))
+}
+
+func (mc *muxConn) lastPong() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&mc.lastPongAt))
+}
+
+// muxStream is a stream on a multiplexed connection.
+type muxStream struct {
+	id       uint64
+	mc       *muxConn
+	ctx      context.Context
+	cancel   cancelCauseFunc
+	recv     *recvBuffer
+	limiter  *rateLimiter
+	queue    *sendQueue
+	counters messageCounters
+
+	mu         sync.Mutex
+	header     metadata.MD
+	headerSent bool
+	trailer    metadata.MD
+	canceled   bool
+	failed     *status.Status
+}
+
+func newMuxStream(mc *muxConn, id uint64, sd *StreamDesc) *muxStream {
+	ms := &muxStream{
+		id:      id,
+		mc:      mc,
+		recv:    newRecvBuffer(mc.s.opts.flowControl.inboundQueue()),
+		limiter: newRateLimiter(mc.s.opts.flowControl),
+		queue:   newSendQueue(mc.s.opts.flowControl, &mc.s.metrics),
+		header:  metadata.MD{},
+		trailer: metadata.MD{},
+	}
+	if ms.queue != nil {
+		ms.queue.start(ms.writeMessage)
+	}
+	ctx := grpc.NewContextWithServerTransportStream(mc.ctx, &serverStreamTransport{
+		method: sd.StreamName,
+		ss:     ms,
+	})
+	ms.ctx, ms.cancel = withCancelCause(ctx)
+	return ms
+}
+
+func (ms *muxStream) SetHeader(md metadata.MD) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ms.header = metadata.Join(ms.header, md)
+	return nil
+}
+
+func (ms *muxStream) SendHeader(md metadata.MD) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ms.header = metadata.Join(ms.header, md)
+	return ms.writeHeaderLocked()
+}
+
+func (ms *muxStream) SetTrailer(md metadata.MD) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.trailer = metadata.Join(ms.trailer, md)
+}
+
+func (ms *muxStream) Context() context.Context {
+	return ms.ctx
+}
+
+func (ms *muxStream) SendMsg(m interface{}) error {
+	b, err := ms.marshal(m.(proto.Message))
+	if err != nil {
+		return err
+	}
+	return ms.sendPrepared(b)
+}
+
+func (ms *muxStream) encoding() string {
+	return encodingJSON
+}
+
+func (ms *muxStream) marshal(m proto.Message) ([]byte, error) {
+	return protojsonMarshalOptions.Marshal(m)
+}
+
+func (ms *muxStream) sendPrepared(b []byte) error {
+	return ms.counters.sent(ms.sendBytes(b))
+}
+
+func (ms *muxStream) sendBytes(b []byte) error {
+	ms.mu.Lock()
+	if err := ms.ctx.Err(); err != nil {
+		ms.mu.Unlock()
+		return toRPCErr(err)
+	}
+	if err := ms.writeHeaderLocked(); err != nil {
+		ms.mu.Unlock()
+		return err
+	}
+	ms.mu.Unlock()
+	if ms.queue != nil {
+		return ms.queue.push(ms.ctx, b)
+	}
+	return ms.writeMessage(b)
+}
+
+func (ms *muxStream) writeMessage(b []byte) error {
+	if err := ms.mc.writeFrame(&streamFrame{
+		ID:      ms.id,
+		Type:    frameMessage,
+		Payload: b,
+	}); err != nil {
+		return err
+	}
+	ms.mc.touch()
+	return nil
+}
+
+func (ms *muxStream) RecvMsg(m interface{}) error {
+	return ms.counters.received(ms.recvMsg(m))
+}
+
+func (ms *muxStream) recvMsg(m interface{}) error {
+	b, err := ms.recv.get(ms.ctx)
+	if err != nil {
+		return err
+	}
+	return protojsonUnmarshalOptions.Unmarshal(b, m.(proto.Message))
+}
+
+// fail cancels the stream, which is finished with st instead of the status
+// returned by the handler.
+func (ms *muxStream) fail(st *status.Status) {
+	ms.mu.Lock()
+	if ms.failed == nil {
+		ms.failed = st
+	}
+	ms.mu.Unlock()
+	if ms.queue != nil {
+		ms.queue.fail(st.Err())
+	}
+	ms.cancel(st.Err())
+}
+
+func (ms *muxStream) abort(st *status.Status) {
+	ms.fail(st)
+}
+
+func (ms *muxStream) transport() string {
+	return TransportMultiplex
+}
+
+func (ms *muxStream) streamCounters() *messageCounters {
+	return &ms.counters
+}
+
+// finish sends trailer along with the final status of the stream, unless the
+// client has cancelled it.
+func (ms *muxStream) finish(st *status.Status) error {
+	ms.cancel(nil)
+	if ms.queue != nil {
+		ms.queue.close()
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.canceled {
+		return nil
+	}
+	if ms.failed != nil {
+		st = ms.failed
+	}
+	if err := ms.writeHeaderLocked(); err != nil {
+		return err
+	}
+	frame, err := newTrailerFrame(ms.trailer, st)
+	if err != nil {
+		return err
+	}
+	frame.ID = ms.id
+	frame.Type = frameTrailer
+	return ms.mc.writeFrame(frame)
+}
+
+func (ms *muxStream) writeHeaderLocked() error {
+	if ms.headerSent {
+		return nil
+	}
+	ms.headerSent = true
+	if len(ms.header) == 0 {
+		return nil
+	}
+	return ms.mc.writeFrame(&streamFrame{
+		ID:     ms.id,
+		Type:   frameHeader,
+		Header: ms.header,
+	})
+}
@@ -0,0 +1,92 @@
+package protoweb
+
+import (
+	"testing"
+
+	"github.com/gobwas/ws/wsutil"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMultiplex(t *testing.T) {
+	_, ts := newTestServer(t, StreamMultiplex("/mux"))
+	c := dialTestConn(t, ts, "/mux", nil)
+
+	c.writeFrame(&streamFrame{ID: 1, Type: frameOpen, Method: "/test.Test/Echo", Payload: []byte(`"a"`)})
+	c.writeFrame(&streamFrame{ID: 2, Type: frameOpen, Method: "/test.Test/Repeat", Payload: []byte(`"b"`)})
+	messages := map[uint64][]string{}
+	closed := false
+	for !closed || len(messages[1]) == 0 {
+		frame := c.readFrame()
+		switch {
+		case frame.Type == frameMessage:
+			messages[frame.ID] = append(messages[frame.ID], string(frame.Payload))
+		case frame.Type == frameTrailer && frame.ID == 2:
+			if code := frameStatus(t, frame).Code(); code != codes.OK {
+				t.Fatalf("stream 2 is closed with %s", code)
+			}
+			closed = true
+		default:
+			t.Fatalf("unexpected frame %+v", frame)
+		}
+	}
+	if got := messages[2]; len(got) != 3 {
+		t.Errorf("messages of stream 2 = %q, want 3", got)
+	}
+	if got := messages[1]; len(got) != 1 || got[0] != `"a"` {
+		t.Errorf("messages of stream 1 = %q, want [\"a\"]", got)
+	}
+
+	c.writeFrame(&streamFrame{ID: 1, Type: frameEnd})
+	if frame := c.readFrame(); frame.ID != 1 || frame.Type != frameTrailer || frameStatus(t, frame).Code() != codes.OK {
+		t.Errorf("frame = %+v, want trailer of stream 1", frame)
+	}
+}
+
+func TestMultiplexMaxConcurrentStreams(t *testing.T) {
+	_, ts := newTestServer(t, StreamMultiplex("/mux"), MaxConcurrentStreams(1))
+	c := dialTestConn(t, ts, "/mux", nil)
+
+	c.writeFrame(&streamFrame{ID: 1, Type: frameOpen, Method: "/test.Test/Echo", Payload: []byte(`"a"`)})
+	if frame := c.readFrame(); frame.ID != 1 || frame.Type != frameMessage {
+		t.Fatalf("frame = %+v, want message of stream 1", frame)
+	}
+	c.writeFrame(&streamFrame{ID: 2, Type: frameOpen, Method: "/test.Test/Echo", Payload: []byte(`"b"`)})
+	frame := c.readFrame()
+	if frame.ID != 2 || frame.Type != frameTrailer || frameStatus(t, frame).Code() != codes.ResourceExhausted {
+		t.Fatalf("frame = %+v, want trailer of stream 2 with %s", frame, codes.ResourceExhausted)
+	}
+
+	// the stream is released before its trailer is sent
+	c.writeFrame(&streamFrame{ID: 1, Type: frameEnd})
+	if frame := c.readFrame(); frame.ID != 1 || frame.Type != frameTrailer {
+		t.Fatalf("frame = %+v, want trailer of stream 1", frame)
+	}
+	c.writeFrame(&streamFrame{ID: 3, Type: frameOpen, Method: "/test.Test/Echo", Payload: []byte(`"c"`)})
+	if frame := c.readFrame(); frame.ID != 3 || frame.Type != frameMessage || string(frame.Payload) != `"c"` {
+		t.Fatalf("frame = %+v, want message of stream 3", frame)
+	}
+}
+
+func TestMultiplexStreamIDZero(t *testing.T) {
+	_, ts := newTestServer(t, StreamMultiplex("/mux"))
+	c := dialTestConn(t, ts, "/mux", nil)
+
+	c.writeFrame(&streamFrame{Type: frameOpen, Method: "/test.Test/Echo", Payload: []byte(`"a"`)})
+	if _, _, err := c.read(); err == nil {
+		t.Fatal("connection is not closed")
+	} else if _, ok := err.(wsutil.ClosedError); !ok {
+		t.Fatalf("err = %v, want a close frame", err)
+	}
+}
+
+func TestMultiplexInboundQueueFull(t *testing.T) {
+	_, ts := newTestServer(t, StreamMultiplex("/mux"), StreamFlowControl(StreamFlowControlParams{InboundQueue: 1}))
+	c := dialTestConn(t, ts, "/mux", nil)
+
+	c.writeFrame(&streamFrame{ID: 1, Type: frameOpen, Method: "/test.Test/Wait", Payload: []byte(`"a"`)})
+	c.writeFrame(&streamFrame{ID: 1, Type: frameMessage, Payload: []byte(`"b"`)})
+	frame := c.readFrame()
+	if frame.ID != 1 || frame.Type != frameTrailer || frameStatus(t, frame).Code() != codes.ResourceExhausted {
+		t.Fatalf("frame = %+v, want trailer of stream 1 with %s", frame, codes.ResourceExhausted)
+	}
+}
@@ -0,0 +1,44 @@
+package protoweb
+
+type serverOptions struct {
+	streamKeepalive      StreamKeepaliveParams
+	multiplexPath        string
+	maxConcurrentStreams uint32
+	streamResume         map[string]StreamResumeParams
+	longPollPath         string
+	longPoll             LongPollParams
+	flowControl          StreamFlowControlParams
+	compression          *StreamCompressionParams
+	maxRecvMsgSize       int
+
+	duplexMaxMessageSize int
+
+	streamAuth          StreamAuthFunc
+	allowedOrigins      []string
+	tokenQueryParam     string
+	tokenProtocolPrefix string
+	protocols           []string
+}
+
+// ServerOption configures how a Server serves requests.
+type ServerOption func(*serverOptions)
+
+// StreamKeepalive sets keepalive and timeout parameters of WebSocket streams.
+func StreamKeepalive(kp StreamKeepaliveParams) ServerOption {
+	return func(o *serverOptions) {
+		o.streamKeepalive = kp
+	}
+}
+
+// MaxRecvMsgSize sets the max size in bytes of a message received on WebSocket
+// streams and multiplexed connections, defaults to 4MB. Larger messages close
+// the connection with ResourceExhausted. It bounds bodies of long-polling
+// requests as well, HTTP/2 streams are bounded by StreamHTTP2.
+func MaxRecvMsgSize(n int) ServerOption {
+	if n <= 0 {
+		n = defaultMaxRecvMsgSize
+	}
+	return func(o *serverOptions) {
+		o.maxRecvMsgSize = n
+	}
+}
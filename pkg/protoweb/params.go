@@ -0,0 +1,160 @@
+package protoweb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// SplitParams splits comma separated values of a parameter, e.g. values of
+// "?tag=a,b&tag=c" are split into "a", "b" and "c".
+func SplitParams(values []string) []string {
+	var result []string
+	for _, v := range values {
+		result = append(result, strings.Split(v, ",")...)
+	}
+	return result
+}
+
+// UnmarshalParam unmarshals m of a well known type from the JSON string form of
+// a parameter, e.g. "2006-01-02T15:04:05Z" for google.protobuf.Timestamp,
+// "1.5s" for google.protobuf.Duration and "a,b.c" for google.protobuf.FieldMask.
+func UnmarshalParam(value string, m proto.Message) error {
+	b := []byte(strconv.Quote(value))
+	if _, ok := m.(*wrapperspb.BoolValue); ok {
+		b = []byte(value)
+	}
+	return protojson.Unmarshal(b, m)
+}
+
+// FormatParam formats m of a well known type in its JSON string form, as the
+// reverse of UnmarshalParam.
+func FormatParam(m proto.Message) string {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	if s, err := strconv.Unquote(string(b)); err == nil {
+		return s
+	}
+	return string(b)
+}
+
+// ParseEnum parses an enum parameter by either its name or number, of values
+// mapping names to numbers. Numbers not in values are invalid too.
+func ParseEnum(value string, values map[string]int32) (int32, error) {
+	if n, ok := values[value]; ok {
+		return n, nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 32); err == nil {
+		for _, v := range values {
+			if v == int32(n) {
+				return v, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid enum value %q", value)
+}
+
+// ParseBytes decodes a bytes parameter in base64, either standard or URL safe,
+// with or without padding, as in JSON.
+func ParseBytes(value string) ([]byte, error) {
+	enc := base64.StdEncoding
+	if strings.ContainsAny(value, "-_") {
+		enc = base64.URLEncoding
+	}
+	if len(value)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return enc.DecodeString(value)
+}
+
+var locationVarRe = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// expandLocation expands template of a Location header with path escaped
+// values of fields of m, e.g. "/users/{id}", and names of enum values.
+func expandLocation(template string, m proto.Message) string {
+	msg := m.ProtoReflect()
+	return locationVarRe.ReplaceAllStringFunc(template, func(v string) string {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(v[1 : len(v)-1]))
+		if fd == nil {
+			return ""
+		}
+		value := msg.Get(fd)
+		s := fmt.Sprint(value.Interface())
+		if fd.Kind() == protoreflect.EnumKind {
+			if ev := fd.Enum().Values().ByNumber(value.Enum()); ev != nil {
+				s = string(ev.Name())
+			} else {
+				s = strconv.Itoa(int(value.Enum()))
+			}
+		}
+		return url.PathEscape(s)
+	})
+}
+
+// ParamErrors collects errors of binding fields from parameters of a request,
+// which are reported together as codes.InvalidArgument, with a
+// google.rpc.BadRequest detail of a violation for each of them.
+type ParamErrors struct {
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+// Add adds err of binding field, in the form of "page.size", from the
+// parameter name in source, which is either path, query, header or cookie.
+func (e *ParamErrors) Add(field, source, name string, err error) {
+	reason := err.Error()
+	if ne, ok := err.(*strconv.NumError); ok {
+		reason = ne.Err.Error()
+	}
+	e.violations = append(e.violations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: fmt.Sprintf("invalid %s parameter %q: %s", source, name, reason),
+	})
+}
+
+// Err returns the error of all parameters added, or nil if there is none.
+func (e *ParamErrors) Err() error {
+	if len(e.violations) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(e.violations))
+	for i, v := range e.violations {
+		descriptions[i] = v.Description
+	}
+	st := status.New(codes.InvalidArgument, strings.Join(descriptions, "; "))
+	if ds, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: e.violations}); err == nil {
+		st = ds
+	}
+	return &paramError{st: st}
+}
+
+// paramError is the error of ParamErrors, which is also responded with
+// http.StatusBadRequest by unary handlers.
+type paramError struct {
+	st *status.Status
+}
+
+func (e *paramError) Error() string {
+	return e.st.Err().Error()
+}
+
+func (e *paramError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+func (e *paramError) HTTPStatus() int {
+	return http.StatusBadRequest
+}
@@ -0,0 +1,46 @@
+package protoweb
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestParseEnum(t *testing.T) {
+	values := map[string]int32{
+		"COLOR_UNSPECIFIED": 0,
+		"RED":               1,
+		"BLUE":              2,
+	}
+	for _, c := range []struct {
+		value string
+		want  int32
+		ok    bool
+	}{
+		{value: "RED", want: 1, ok: true},
+		{value: "2", want: 2, ok: true},
+		{value: "0", want: 0, ok: true},
+		{value: "99"},
+		{value: "-1"},
+		{value: "GREEN"},
+		{value: ""},
+	} {
+		n, err := ParseEnum(c.value, values)
+		if (err == nil) != c.ok {
+			t.Errorf("ParseEnum(%q) err = %v, want ok %v", c.value, err, c.ok)
+		} else if n != c.want {
+			t.Errorf("ParseEnum(%q) = %d, want %d", c.value, n, c.want)
+		}
+	}
+}
+
+func TestExpandLocation(t *testing.T) {
+	m := &typepb.Field{
+		Kind:   typepb.Field_TYPE_STRING,
+		Number: 7,
+		Name:   "a/b c",
+	}
+	if loc := expandLocation("/fields/{number}/{kind}/{name}", m); loc != "/fields/7/TYPE_STRING/a%2Fb%20c" {
+		t.Errorf("location = %q", loc)
+	}
+}
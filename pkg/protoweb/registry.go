@@ -0,0 +1,172 @@
+package protoweb
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// transports of streams, as reported by StreamInfo.
+const (
+	TransportWebSocket = "websocket"
+	TransportMultiplex = "multiplex"
+	TransportLongPoll  = "longpoll"
+	TransportHTTP2     = "http2"
+	TransportDownload  = "download"
+)
+
+// StreamInfo describes a stream being served.
+type StreamInfo struct {
+	// ID identifies the stream for CloseStream.
+	ID uint64
+	// Method is the full method name, in the form of
+	// "/package.Service/Method".
+	Method string
+	// Transport is one of TransportWebSocket, TransportMultiplex,
+	// TransportLongPoll, TransportHTTP2 and TransportDownload.
+	Transport string
+	// Peer is the client of the stream.
+	Peer *peer.Peer
+	// StartTime is when the handler was started.
+	StartTime time.Time
+	// MessagesSent counts messages sent by the handler.
+	MessagesSent uint64
+	// MessagesReceived counts messages received by the handler.
+	MessagesReceived uint64
+	// Context is the stream context, carrying values set by StreamAuthFunc,
+	// e.g. to tell which tenant the stream belongs to.
+	Context context.Context
+}
+
+// Streams returns streams currently served, ordered by ID.
+func (s *Server) Streams() []StreamInfo {
+	s.streams.mu.Lock()
+	infos := make([]StreamInfo, 0, len(s.streams.active))
+	for _, as := range s.streams.active {
+		infos = append(infos, as.info())
+	}
+	s.streams.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// CloseStream closes the stream of id with st, which is sent to the client as
+// the final status. The handler is cancelled with st.Err() as the cause, and
+// what it returns is discarded. It returns NotFound if there is no such
+// stream.
+func (s *Server) CloseStream(id uint64, st *status.Status) error {
+	s.streams.mu.Lock()
+	as, ok := s.streams.active[id]
+	s.streams.mu.Unlock()
+	if !ok {
+		return status.Errorf(codes.NotFound, "stream %d is not found", id)
+	}
+	as.stream.abort(st)
+	return nil
+}
+
+// CloseStreams closes every stream matched by match with st, and returns how
+// many are closed.
+func (s *Server) CloseStreams(match func(StreamInfo) bool, st *status.Status) int {
+	n := 0
+	for _, info := range s.Streams() {
+		if match(info) && s.CloseStream(info.ID, st) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// registeredStream is a stream served by a transport of this package.
+type registeredStream interface {
+	grpc.ServerStream
+	transport() string
+	streamCounters() *messageCounters
+	// abort closes the stream with st while the handler may still be
+	// running.
+	abort(st *status.Status)
+}
+
+type streamRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	active map[uint64]*activeStream
+}
+
+func (r *streamRegistry) add(method string, rs registeredStream) *activeStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active == nil {
+		r.active = map[uint64]*activeStream{}
+	}
+	r.nextID++
+	as := &activeStream{
+		id:        r.nextID,
+		method:    method,
+		startTime: time.Now(),
+		stream:    rs,
+	}
+	r.active[as.id] = as
+	return as
+}
+
+func (r *streamRegistry) remove(as *activeStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.active, as.id)
+}
+
+type activeStream struct {
+	id        uint64
+	method    string
+	startTime time.Time
+	stream    registeredStream
+}
+
+func (as *activeStream) info() StreamInfo {
+	ctx := as.stream.Context()
+	p, _ := peer.FromContext(ctx)
+	c := as.stream.streamCounters()
+	return StreamInfo{
+		ID:               as.id,
+		Method:           as.method,
+		Transport:        as.stream.transport(),
+		Peer:             p,
+		StartTime:        as.startTime,
+		MessagesSent:     atomic.LoadUint64(&c.sentCount),
+		MessagesReceived: atomic.LoadUint64(&c.receivedCount),
+		Context:          ctx,
+	}
+}
+
+// messageCounters counts messages of a stream.
+type messageCounters struct {
+	// accessed atomically
+	sentCount     uint64
+	receivedCount uint64
+}
+
+// sent counts a sent message unless err is not nil, and returns err.
+func (c *messageCounters) sent(err error) error {
+	if err == nil {
+		atomic.AddUint64(&c.sentCount, 1)
+	}
+	return err
+}
+
+// received counts a received message unless err is not nil, and returns err.
+func (c *messageCounters) received(err error) error {
+	if err == nil {
+		atomic.AddUint64(&c.receivedCount, 1)
+	}
+	return err
+}
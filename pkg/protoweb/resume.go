@@ -0,0 +1,537 @@
+package protoweb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// resumeTokenKey is the header metadata carrying the token of a resumable
+	// stream.
+	resumeTokenKey = "resume-token"
+	// lastEventIDParam is the query parameter to resume a stream with, for
+	// clients unable to set the Last-Event-ID header.
+	lastEventIDParam = "last_event_id"
+
+	defaultResumeWindow      = 30 * time.Second
+	defaultResumeMaxMessages = 128
+)
+
+var errStreamExpired = status.Error(codes.Unavailable, "stream has expired")
+
+// StreamResumeParams controls how server streams are resumed after the
+// connection is lost. Zero values use defaults.
+type StreamResumeParams struct {
+	// Window is how long a stream is kept running without a connection,
+	// defaults to 30 seconds.
+	Window time.Duration
+	// MaxMessages is how many sent messages are kept for replay, defaults
+	// to 128.
+	MaxMessages int
+}
+
+// StreamResume makes server-only streams resumable, methods are in the form of
+// "/package.Service/Method", and every server-only stream if none is given.
+//
+// Messages of a resumable stream are sent as message frames stamped with a
+// sequence number, and the header carries a "resume-token". A client which
+// lost the connection reconnects to the same path with the Last-Event-ID
+// header, or the last_event_id query parameter, set to "<token>:<seq>" of the
+// last message it received, and gets the missed messages before live delivery
+// continues. A stream is only resumed by the principal it was opened by.
+func StreamResume(rp StreamResumeParams, methods ...string) ServerOption {
+	if rp.Window <= 0 {
+		rp.Window = defaultResumeWindow
+	}
+	if rp.MaxMessages <= 0 {
+		rp.MaxMessages = defaultResumeMaxMessages
+	}
+	return func(o *serverOptions) {
+		if o.streamResume == nil {
+			o.streamResume = map[string]StreamResumeParams{}
+		}
+		if len(methods) == 0 {
+			o.streamResume[""] = rp
+		}
+		for _, m := range methods {
+			o.streamResume[m] = rp
+		}
+	}
+}
+
+func (o *serverOptions) resumeParams(si *serviceInfo, sd *StreamDesc) (StreamResumeParams, bool) {
+	if sd.ClientStreams || o.streamResume == nil {
+		return StreamResumeParams{}, false
+	}
+	if rp, ok := o.streamResume["/"+si.name+"/"+sd.StreamName]; ok {
+		return rp, true
+	}
+	rp, ok := o.streamResume[""]
+	return rp, ok
+}
+
+func (s *Server) processResumableStream(ctx context.Context, w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, sd *StreamDesc, rp StreamResumeParams) {
+	ss := newServerStream(ctx, s, sd, w, r, params)
+	ss.framed = true
+	defer ss.drop()
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get(lastEventIDParam)
+	}
+	if lastEventID != "" {
+		rs, err := s.resumes.resume(ss, lastEventID)
+		if err != nil {
+			st, _ := status.FromError(toRPCErr(err))
+			if err := ss.finish(st); err != nil {
+				s.logger.Debugf("stream %q: %s", sd.StreamName, err)
+			}
+			return
+		}
+		rs.watch(ss)
+		return
+	}
+
+	token, err := newSessionToken()
+	if err != nil {
+		if err := ss.finish(status.New(codes.Internal, err.Error())); err != nil {
+			s.logger.Debugf("stream %q: %s", sd.StreamName, err)
+		}
+		return
+	}
+	rs := newResumeSession(s, token, sd, ss, rp)
+	s.resumes.add(rs)
+	if sd.Binder != nil {
+		// otherwise the request is read from the connection, see recvMsg
+		go rs.watch(ss)
+	}
+
+	err = s.handleStream(si, sd, rs)
+	st, _ := status.FromError(toRPCErr(err))
+	if err := rs.finish(st); err != nil {
+		s.logger.Debugf("stream %q: %s", sd.StreamName, err)
+	}
+}
+
+func newSessionToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// resumeRegistry keeps resumable streams by their tokens.
+type resumeRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*resumeSession
+}
+
+func (rr *resumeRegistry) add(rs *resumeSession) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.sessions == nil {
+		rr.sessions = map[string]*resumeSession{}
+	}
+	rr.sessions[rs.token] = rs
+}
+
+func (rr *resumeRegistry) remove(token string) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	delete(rr.sessions, token)
+}
+
+// resume attaches ss to the stream identified by lastEventID, in the form of
+// "<token>:<seq>".
+func (rr *resumeRegistry) resume(ss *serverStream, lastEventID string) (*resumeSession, error) {
+	pos := strings.LastIndex(lastEventID, ":")
+	if pos < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed last event id %q", lastEventID)
+	}
+	seq, err := strconv.ParseUint(lastEventID[pos+1:], 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed last event id %q", lastEventID)
+	}
+
+	rr.mu.Lock()
+	rs, ok := rr.sessions[lastEventID[:pos]]
+	rr.mu.Unlock()
+	if !ok || rs.desc != ss.desc {
+		return nil, status.Error(codes.NotFound, "stream is not found or has expired")
+	}
+	if principal, _ := PrincipalFromContext(ss.ctx); principal != rs.principal {
+		return nil, status.Error(codes.PermissionDenied, "stream is opened by another principal")
+	}
+	if err := rs.attach(ss, seq); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+type resumeMessage struct {
+	seq     uint64
+	payload []byte
+}
+
+// resumeSession is a resumable server stream. It outlives the connections it
+// is sent over, and keeps sent messages for replay.
+type resumeSession struct {
+	s         *Server
+	token     string
+	principal string
+	desc      *StreamDesc
+	params    StreamResumeParams
+	first     *serverStream
+	ctx       context.Context
+	cancel    cancelCauseFunc
+	counters  messageCounters
+
+	// sendMu serializes writes to attached streams, which are made without
+	// holding mu for a stalled client not to block the stream.
+	sendMu     sync.Mutex
+	mu         sync.Mutex
+	header     metadata.MD
+	headerSent bool
+	headerTo   *serverStream
+	trailer    metadata.MD
+	seq        uint64
+	buffer     []resumeMessage
+	attached   *serverStream
+	timer      *time.Timer
+	finished   bool
+	status     *status.Status
+	expired    bool
+	received   bool
+}
+
+func newResumeSession(s *Server, token string, sd *StreamDesc, ss *serverStream, rp StreamResumeParams) *resumeSession {
+	principal, _ := PrincipalFromContext(ss.ctx)
+	rs := &resumeSession{
+		s:         s,
+		token:     token,
+		principal: principal,
+		desc:      sd,
+		params:    rp,
+		first:     ss,
+		header:    metadata.MD{},
+		trailer:   metadata.MD{},
+		attached:  ss,
+	}
+	// the stream is not bound to the request it was started with
+	ctx := grpc.NewContextWithServerTransportStream(detachContext(ss.ctx), &serverStreamTransport{
+		method: sd.StreamName,
+		ss:     rs,
+	})
+	rs.ctx, rs.cancel = withCancelCause(ctx)
+	go func() {
+		select {
+		case <-s.done:
+			rs.mu.Lock()
+			defer rs.mu.Unlock()
+			rs.removeLocked(ErrServerStopped)
+		case <-rs.ctx.Done():
+		}
+	}()
+	return rs
+}
+
+func (rs *resumeSession) SetHeader(md metadata.MD) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	rs.header = metadata.Join(rs.header, md)
+	return nil
+}
+
+func (rs *resumeSession) SendHeader(md metadata.MD) error {
+	rs.sendMu.Lock()
+	defer rs.sendMu.Unlock()
+	rs.mu.Lock()
+	if rs.headerSent {
+		rs.mu.Unlock()
+		return ErrIllegalHeaderWrite
+	}
+	rs.header = metadata.Join(rs.header, md)
+	rs.headerSent = true
+	ss, header := rs.attached, rs.headerFrameLocked()
+	rs.mu.Unlock()
+	if err := rs.sendTo(ss, header, nil); err != nil {
+		rs.detach(ss)
+	}
+	return nil
+}
+
+func (rs *resumeSession) SetTrailer(md metadata.MD) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.trailer = metadata.Join(rs.trailer, md)
+}
+
+func (rs *resumeSession) Context() context.Context {
+	return rs.ctx
+}
+
+// SendMsg stamps m with the next sequence number and keeps it for replay. It
+// does not fail while the client is disconnected, until the stream expires.
+func (rs *resumeSession) SendMsg(m interface{}) error {
+	b, err := rs.marshal(m.(proto.Message))
+	if err != nil {
+		return err
+	}
+	return rs.sendPrepared(b)
+}
+
+func (rs *resumeSession) encoding() string {
+	return encodingJSON
+}
+
+func (rs *resumeSession) marshal(m proto.Message) ([]byte, error) {
+	return protojsonMarshalOptions.Marshal(m)
+}
+
+func (rs *resumeSession) sendPrepared(b []byte) error {
+	return rs.counters.sent(rs.sendBytes(b))
+}
+
+func (rs *resumeSession) sendBytes(b []byte) error {
+	rs.sendMu.Lock()
+	defer rs.sendMu.Unlock()
+	rs.mu.Lock()
+	if err := rs.ctx.Err(); err != nil {
+		rs.mu.Unlock()
+		return toRPCErr(Cause(rs.ctx))
+	}
+	rs.seq++
+	m := resumeMessage{
+		seq:     rs.seq,
+		payload: b,
+	}
+	rs.buffer = append(rs.buffer, m)
+	if n := len(rs.buffer) - rs.params.MaxMessages; n > 0 {
+		rs.buffer = append(rs.buffer[:0:0], rs.buffer[n:]...)
+	}
+	rs.headerSent = true
+	ss, header := rs.attached, rs.headerFrameLocked()
+	rs.mu.Unlock()
+	if err := rs.sendTo(ss, header, []resumeMessage{m}); err != nil {
+		rs.detach(ss)
+	}
+	return nil
+}
+
+// RecvMsg binds the request from the connection the stream was started with,
+// nothing else is received.
+func (rs *resumeSession) RecvMsg(m interface{}) error {
+	return rs.counters.received(rs.recvMsg(m))
+}
+
+func (rs *resumeSession) recvMsg(m interface{}) error {
+	rs.mu.Lock()
+	received := rs.received
+	rs.received = true
+	rs.mu.Unlock()
+	if received {
+		return io.EOF
+	}
+	err := rs.first.RecvMsg(m)
+	if rs.desc.Binder == nil {
+		// the connection is watched once the request is read from it, for
+		// the watcher not to take the request
+		go rs.watch(rs.first)
+	}
+	return err
+}
+
+// finish sends trailer along with the final status of the stream if a client
+// is connected, or keeps them until the client resumes or the stream expires.
+func (rs *resumeSession) finish(st *status.Status) error {
+	rs.sendMu.Lock()
+	defer rs.sendMu.Unlock()
+	rs.mu.Lock()
+	rs.cancel(nil)
+	rs.finished = true
+	rs.status = st
+	if rs.attached == nil {
+		rs.mu.Unlock()
+		return nil
+	}
+	rs.headerSent = true
+	ss, header, trailer := rs.attached, rs.headerFrameLocked(), rs.trailer
+	rs.removeLocked(nil)
+	rs.mu.Unlock()
+	return rs.finishOn(ss, header, trailer, st)
+}
+
+// finishOn sends the final status of the stream to ss.
+func (rs *resumeSession) finishOn(ss *serverStream, header *streamFrame, trailer metadata.MD, st *status.Status) error {
+	if err := rs.sendTo(ss, header, nil); err != nil {
+		return err
+	}
+	ss.SetTrailer(trailer)
+	return ss.finish(st)
+}
+
+// abort closes the connection the stream is attached to with st, and expires
+// the stream.
+func (rs *resumeSession) abort(st *status.Status) {
+	rs.mu.Lock()
+	ss := rs.attached
+	rs.attached = nil
+	rs.removeLocked(st.Err())
+	rs.mu.Unlock()
+	if ss != nil {
+		ss.abort(st)
+	}
+}
+
+func (rs *resumeSession) transport() string {
+	return TransportWebSocket
+}
+
+func (rs *resumeSession) streamCounters() *messageCounters {
+	return &rs.counters
+}
+
+// attach continues the stream on ss, replaying messages after seq.
+func (rs *resumeSession) attach(ss *serverStream, seq uint64) error {
+	rs.sendMu.Lock()
+	defer rs.sendMu.Unlock()
+	rs.mu.Lock()
+	if rs.expired {
+		rs.mu.Unlock()
+		return status.Error(codes.NotFound, "stream is not found or has expired")
+	}
+	if seq > rs.seq {
+		rs.mu.Unlock()
+		return status.Errorf(codes.OutOfRange, "message %d has not been sent", seq)
+	}
+	if seq < rs.seq && (len(rs.buffer) == 0 || rs.buffer[0].seq > seq+1) {
+		rs.mu.Unlock()
+		return status.Errorf(codes.OutOfRange, "messages after %d are no longer available", seq)
+	}
+
+	// the previous client is taken over
+	previous := rs.attached
+	if rs.timer != nil {
+		rs.timer.Stop()
+		rs.timer = nil
+	}
+	rs.attached = ss
+	// header is sent right away, for the connection to be upgraded
+	rs.headerSent = true
+	header := rs.headerFrameLocked()
+	var replay []resumeMessage
+	for _, m := range rs.buffer {
+		if m.seq > seq {
+			replay = append(replay, m)
+		}
+	}
+	finished, trailer, st := rs.finished, rs.trailer, rs.status
+	if finished {
+		rs.removeLocked(nil)
+	}
+	rs.mu.Unlock()
+
+	if previous != nil {
+		previous.drop()
+	}
+	if err := rs.sendTo(ss, header, replay); err != nil {
+		rs.detach(ss)
+		return nil
+	}
+	if finished {
+		if err := rs.finishOn(ss, nil, trailer, st); err != nil {
+			rs.s.logger.Debugf("stream %q: %s", rs.desc.StreamName, err)
+		}
+	}
+	return nil
+}
+
+// sendTo sends header if not nil, followed by messages to ss. It is called
+// with sendMu held.
+func (rs *resumeSession) sendTo(ss *serverStream, header *streamFrame, messages []resumeMessage) error {
+	if ss == nil {
+		return nil
+	}
+	if header != nil {
+		if err := ss.sendFrame(header); err != nil {
+			return err
+		}
+	}
+	for _, m := range messages {
+		if err := ss.sendMessageFrame(m.seq, m.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watch detaches ss once its connection is lost, messages from the client are
+// discarded.
+func (rs *resumeSession) watch(ss *serverStream) {
+	for {
+		if _, err := ss.recv.get(ss.ctx); err != nil {
+			break
+		}
+	}
+	rs.detach(ss)
+}
+
+// detach drops ss, and expires the stream unless a client resumes it within
+// the window.
+func (rs *resumeSession) detach(ss *serverStream) {
+	rs.mu.Lock()
+	if rs.attached != ss || rs.expired {
+		rs.mu.Unlock()
+		return
+	}
+	rs.attached = nil
+	rs.timer = time.AfterFunc(rs.params.Window, rs.expire)
+	rs.mu.Unlock()
+	ss.drop()
+}
+
+func (rs *resumeSession) expire() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.attached != nil || rs.expired {
+		return
+	}
+	rs.removeLocked(errStreamExpired)
+}
+
+func (rs *resumeSession) removeLocked(cause error) {
+	rs.expired = true
+	if rs.timer != nil {
+		rs.timer.Stop()
+	}
+	rs.s.resumes.remove(rs.token)
+	rs.cancel(cause)
+}
+
+// headerFrameLocked returns header along with the resume token, once for
+// every connection the stream is attached to, or nil if it has been sent.
+func (rs *resumeSession) headerFrameLocked() *streamFrame {
+	if rs.attached == nil || rs.headerTo == rs.attached {
+		return nil
+	}
+	rs.headerTo = rs.attached
+	return newHeaderFrame(metadata.Join(rs.header, metadata.Pairs(resumeTokenKey, rs.token)))
+}
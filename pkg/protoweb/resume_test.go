@@ -0,0 +1,56 @@
+package protoweb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gobwas/ws"
+	"google.golang.org/grpc/codes"
+)
+
+func TestResumeRequestMessage(t *testing.T) {
+	_, ts := newTestServer(t, StreamResume(StreamResumeParams{}))
+	c := dialTestConn(t, ts, "/repeat", nil)
+
+	c.write(ws.OpText, []byte(`"a"`))
+	if frame := c.readFrame(); len(frame.Header[resumeTokenKey]) != 1 {
+		t.Fatalf("frame = %+v, want header with the resume token", frame)
+	}
+	for i := uint64(1); i <= 3; i++ {
+		frame := c.readFrame()
+		if frame.Type != frameMessage || frame.Seq != i || string(frame.Payload) != `"a"` {
+			t.Fatalf("frame = %+v, want message %d", frame, i)
+		}
+	}
+	if frame := c.readFrame(); frame.Status == nil || frameStatus(t, frame).Code() != codes.OK {
+		t.Fatalf("frame = %+v, want trailer", frame)
+	}
+}
+
+func TestResumePrincipal(t *testing.T) {
+	_, ts := newTestServer(t, StreamResume(StreamResumeParams{}), StreamAuth(func(ctx context.Context, r *http.Request, token string) (context.Context, error) {
+		return WithPrincipal(ctx, "user-"+token), nil
+	}))
+	c := dialTestConn(t, ts, "/watch", http.Header{"Authorization": {"Bearer 1"}})
+	c.write(ws.OpText, []byte(`"a"`))
+	header := c.readFrame()
+	if len(header.Header[resumeTokenKey]) != 1 {
+		t.Fatalf("frame = %+v, want header with the resume token", header)
+	}
+	if frame := c.readFrame(); frame.Type != frameMessage || frame.Seq != 1 {
+		t.Fatalf("frame = %+v, want message 1", frame)
+	}
+	_ = c.conn.Close()
+
+	lastEventID := header.Header[resumeTokenKey][0] + ":1"
+	c = dialTestConn(t, ts, "/watch", http.Header{"Authorization": {"Bearer 2"}, "Last-Event-Id": {lastEventID}})
+	if frame := c.readFrame(); frame.Status == nil || frameStatus(t, frame).Code() != codes.PermissionDenied {
+		t.Fatalf("frame = %+v, want trailer with %s", frame, codes.PermissionDenied)
+	}
+
+	c = dialTestConn(t, ts, "/watch", http.Header{"Authorization": {"Bearer 1"}, "Last-Event-Id": {lastEventID}})
+	if frame := c.readFrame(); len(frame.Header[resumeTokenKey]) != 1 {
+		t.Fatalf("frame = %+v, want header with the resume token", frame)
+	}
+}
@@ -0,0 +1,56 @@
+package protoweb
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleRoute registers handle for method and path, routes with a custom verb
+// are kept in a router of their own for that verb.
+func (s *Server) handleRoute(method, path, verb string, handle httprouter.Handle) {
+	key := method + " " + path + ":" + verb
+	if s.routes[key] {
+		s.logger.Fatalf("proto-web: Server.RegisterService found duplicate route %s %s:%s", method, path, verb)
+	}
+	s.routes[key] = true
+	if verb == "" {
+		s.router.Handle(method, path, handle)
+		return
+	}
+	router, ok := s.verbs[verb]
+	if !ok {
+		router = httprouter.New()
+		s.verbs[verb] = router
+	}
+	router.Handle(method, path, handle)
+}
+
+// verbRoute returns the router of the custom verb in the last segment of the
+// path of r, e.g. "/v1/operations/1:cancel", along with r routed as
+// "/v1/operations/1". It returns false unless a route declares the verb for
+// the stripped path.
+func (s *Server) verbRoute(r *http.Request) (*httprouter.Router, *http.Request, bool) {
+	if len(s.verbs) == 0 {
+		return nil, nil, false
+	}
+	p := r.URL.Path
+	i := strings.LastIndexByte(p, ':')
+	if i < 0 || i < strings.LastIndexByte(p, '/') {
+		return nil, nil, false
+	}
+	router, ok := s.verbs[p[i+1:]]
+	if !ok {
+		return nil, nil, false
+	}
+	if handle, _, _ := router.Lookup(r.Method, p[:i]); handle == nil {
+		return nil, nil, false
+	}
+	u := *r.URL
+	u.Path = p[:i]
+	u.RawPath = ""
+	r2 := r.WithContext(r.Context())
+	r2.URL = &u
+	return router, r2, true
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/gobwas/ws"
@@ -20,6 +21,9 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// defaultMaxRecvMsgSize is the max size of a received WebSocket message.
+const defaultMaxRecvMsgSize = 4 << 20
+
 var (
 	protojsonMarshalOptions = protojson.MarshalOptions{
 		AllowPartial:    false,
@@ -40,8 +44,17 @@ type ServiceRegistrar interface {
 type Server struct {
 	mu       sync.Mutex
 	router   *httprouter.Router
+	routes   map[string]bool
+	verbs    map[string]*httprouter.Router
 	upgrader *ws.HTTPUpgrader
 	services map[string]*serviceInfo
+	opts     serverOptions
+	resumes  resumeRegistry
+	polls    pollRegistry
+	metrics  streamMetrics
+	streams  streamRegistry
+	done     chan struct{}
+	stopOnce sync.Once
 
 	logger *zap.SugaredLogger
 
@@ -49,24 +62,56 @@ type Server struct {
 	streamInterceptor grpc.StreamServerInterceptor
 }
 
-func NewServer() *Server {
+func NewServer(opts ...ServerOption) *Server {
 	logger, err := zap.NewDevelopment()
 	if err != nil {
 		panic(err)
 	}
-	return &Server{
+	s := &Server{
 		router:   httprouter.New(),
+		routes:   map[string]bool{},
+		verbs:    map[string]*httprouter.Router{},
 		upgrader: &ws.HTTPUpgrader{},
 		services: map[string]*serviceInfo{},
+		done:     make(chan struct{}),
 
 		logger: logger.Sugar(),
 	}
+	s.opts.maxRecvMsgSize = defaultMaxRecvMsgSize
+	s.opts.maxConcurrentStreams = defaultMaxConcurrentStreams
+	s.opts.tokenProtocolPrefix = defaultTokenProtocolPrefix
+	for _, o := range opts {
+		o(&s.opts)
+	}
+	if s.opts.multiplexPath != "" {
+		s.router.GET(s.opts.multiplexPath, s.processMultiplexRequest)
+	}
+	if p := s.opts.longPollPath; p != "" {
+		p = strings.TrimSuffix(p, "/") + "/:session"
+		s.router.GET(p, s.processPollRequest)
+		s.router.POST(p, s.processPollRequest)
+		s.router.DELETE(p, s.processPollRequest)
+	}
+	return s
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if router, r, ok := s.verbRoute(r); ok {
+		router.ServeHTTP(w, r)
+		return
+	}
 	s.router.ServeHTTP(w, r)
 }
 
+// Stop closes every open stream with ErrServerStopped, and rejects new ones.
+// Unary requests are still served, the http.Server serving s is to be shut
+// down by the caller.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
 func (s *Server) RegisterService(sd *ServiceDesc, ss interface{}) {
 	if ss != nil {
 		ht := reflect.TypeOf(sd.HandlerType).Elem()
@@ -89,6 +134,7 @@ func (s *Server) register(sd *ServiceDesc, ss interface{}) {
 	}
 
 	info := &serviceInfo{
+		name:        sd.ServiceName,
 		serviceImpl: ss,
 		methods:     make(map[string]*MethodDesc),
 		streams:     make(map[string]*StreamDesc),
@@ -97,7 +143,7 @@ func (s *Server) register(sd *ServiceDesc, ss interface{}) {
 	for i := range sd.Methods {
 		d := &sd.Methods[i]
 		info.methods[d.MethodName] = d
-		s.router.Handle(d.HttpMethod, d.Path, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		s.handleRoute(d.HttpMethod, d.Path, d.Verb, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 			s.processUnaryRequest(w, r, params, info, d)
 		})
 	}
@@ -107,23 +153,17 @@ func (s *Server) register(sd *ServiceDesc, ss interface{}) {
 		s.router.GET(d.Path, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 			s.processStreamRequest(w, r, params, info, d)
 		})
+		if s.opts.longPollPath != "" || s.opts.duplexMaxMessageSize > 0 {
+			s.router.POST(d.Path, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+				s.processStreamPost(w, r, params, info, d)
+			})
+		}
 	}
 	s.services[sd.ServiceName] = info
 }
 
 func (s *Server) processUnaryRequest(w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, md *MethodDesc) (err error) {
-	ctx := r.Context()
-	pr := &peer.Peer{}
-	{
-		host, port, _ := net.SplitHostPort(r.RemoteAddr)
-		ip := net.ParseIP(host)
-		p, _ := strconv.ParseInt(port, 10, 32)
-		pr.Addr = &net.TCPAddr{
-			IP:   ip,
-			Port: int(p),
-		}
-	}
-	ctx = peer.NewContext(ctx, pr)
+	ctx := peer.NewContext(r.Context(), newPeer(r))
 
 	transport := newTransportStream(md.MethodName, w, r)
 	r = r.WithContext(grpc.NewContextWithServerTransportStream(ctx, transport))
@@ -147,9 +187,18 @@ func (s *Server) processUnaryRequest(w http.ResponseWriter, r *http.Request, par
 		b, _ := protojsonMarshalOptions.Marshal(st.Proto())
 		_, _ = w.Write(b)
 	} else {
-		w.WriteHeader(http.StatusOK)
-		b, _ := protojsonMarshalOptions.Marshal(resp.(proto.Message))
-		_, _ = w.Write(b)
+		httpStatus := http.StatusOK
+		if md.Status != 0 {
+			httpStatus = md.Status
+		}
+		if httpStatus == http.StatusCreated && md.Location != "" {
+			w.Header().Set("Location", expandLocation(md.Location, resp.(proto.Message)))
+		}
+		w.WriteHeader(httpStatus)
+		if httpStatus != http.StatusNoContent {
+			b, _ := protojsonMarshalOptions.Marshal(resp.(proto.Message))
+			_, _ = w.Write(b)
+		}
 	}
 
 	transport.isSent = true
@@ -158,47 +207,86 @@ func (s *Server) processUnaryRequest(w http.ResponseWriter, r *http.Request, par
 }
 
 func (s *Server) processStreamRequest(w http.ResponseWriter, r *http.Request, params httprouter.Params, si *serviceInfo, sd *StreamDesc) (err error) {
-	ctx := r.Context()
-	pr := &peer.Peer{}
-	{
-		host, port, _ := net.SplitHostPort(r.RemoteAddr)
-		ip := net.ParseIP(host)
-		p, _ := strconv.ParseInt(port, 10, 32)
-		pr.Addr = &net.TCPAddr{
-			IP:   ip,
-			Port: int(p),
-		}
-	}
-	ctx = peer.NewContext(ctx, pr)
-
-	transport := newTransportStream(sd.StreamName, w, r)
-	ctx = grpc.NewContextWithServerTransportStream(ctx, transport)
+	ctx := peer.NewContext(r.Context(), newPeer(r))
 
-	conn, _, _, err := s.upgrader.Upgrade(r, w)
-	if err != nil {
-		st := status.New(codes.Unknown, err.Error())
+	if sd.Chunk != nil && r.Header.Get("Upgrade") == "" {
+		s.processDownloadRequest(ctx, w, r, params, si, sd)
+		return nil
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		st := status.New(codes.InvalidArgument, "websocket upgrade required")
 		err = st.Err()
 		b, _ := protojsonMarshalOptions.Marshal(st.Proto())
 		w.WriteHeader(http.StatusBadRequest)
 		_, _ = w.Write(b)
 		return
 	}
+	ctx, ok := s.authorizeStream(ctx, w, r, true)
+	if !ok {
+		return
+	}
 
-	ss := newServerStream(ctx, conn)
+	if rp, ok := s.opts.resumeParams(si, sd); ok {
+		s.processResumableStream(ctx, w, r, params, si, sd, rp)
+		return nil
+	}
 
-	if s.streamInterceptor == nil {
-		err = sd.Handler(si.serviceImpl, ss)
-	} else {
-		info := &grpc.StreamServerInfo{
-			FullMethod:     sd.StreamName,
-			IsClientStream: sd.ClientStreams,
-			IsServerStream: sd.ServerStreams,
-		}
-		err = s.streamInterceptor(si.serviceImpl, ss, info, sd.Handler)
+	ss := newServerStream(ctx, s, sd, w, r, params)
+	err = s.handleStream(si, sd, ss)
+	st, _ := status.FromError(toRPCErr(err))
+	if err := ss.finish(st); err != nil {
+		s.logger.Debugf("stream %q: %s", sd.StreamName, err)
 	}
 	return nil
 }
 
+func (s *Server) handleStream(si *serviceInfo, sd *StreamDesc, ss registeredStream) error {
+	as := s.streams.add("/"+si.name+"/"+sd.StreamName, ss)
+	defer s.streams.remove(as)
+	if s.streamInterceptor == nil {
+		return sd.Handler(si.serviceImpl, ss)
+	}
+	info := &grpc.StreamServerInfo{
+		FullMethod:     sd.StreamName,
+		IsClientStream: sd.ClientStreams,
+		IsServerStream: sd.ServerStreams,
+	}
+	return s.streamInterceptor(si.serviceImpl, ss, info, sd.Handler)
+}
+
+// lookupStream finds a stream by its full method name, in the form of
+// "/package.Service/Method".
+func (s *Server) lookupStream(method string) (*serviceInfo, *StreamDesc, bool) {
+	method = strings.TrimPrefix(method, "/")
+	pos := strings.LastIndex(method, "/")
+	if pos < 0 {
+		return nil, nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	si, ok := s.services[method[:pos]]
+	if !ok {
+		return nil, nil, false
+	}
+	sd, ok := si.streams[method[pos+1:]]
+	if !ok {
+		return nil, nil, false
+	}
+	return si, sd, true
+}
+
+func newPeer(r *http.Request) *peer.Peer {
+	host, port, _ := net.SplitHostPort(r.RemoteAddr)
+	ip := net.ParseIP(host)
+	p, _ := strconv.ParseInt(port, 10, 32)
+	return &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   ip,
+			Port: int(p),
+		},
+	}
+}
+
 func toRPCErr(err error) error {
 	if err == nil || err == io.EOF {
 		return err
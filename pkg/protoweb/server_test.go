@@ -0,0 +1,177 @@
+package protoweb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testServiceDesc has streams of every kind, on wrapperspb.StringValue.
+var testServiceDesc = ServiceDesc{
+	ServiceName: "test.Test",
+	Streams: []StreamDesc{{
+		// Echo sends back every message received.
+		StreamName:    "Echo",
+		Path:          "/echo",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			for {
+				m := &wrapperspb.StringValue{}
+				if err := stream.RecvMsg(m); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if err := stream.SendMsg(m); err != nil {
+					return err
+				}
+			}
+		},
+	}, {
+		// Repeat sends the request back three times.
+		StreamName:    "Repeat",
+		Path:          "/repeat",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			m := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(m); err != nil {
+				return err
+			}
+			for i := 0; i < 3; i++ {
+				if err := stream.SendMsg(m); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, {
+		// Watch sends the request back, and waits until the stream is
+		// closed.
+		StreamName:    "Watch",
+		Path:          "/watch",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			m := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(m); err != nil {
+				return err
+			}
+			if err := stream.SendMsg(m); err != nil {
+				return err
+			}
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		},
+	}, {
+		// Wait waits until the stream is closed.
+		StreamName:    "Wait",
+		Path:          "/wait",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		},
+	}},
+}
+
+func newTestServer(t *testing.T, opts ...ServerOption) (*Server, *httptest.Server) {
+	s := NewServer(opts...)
+	s.register(&testServiceDesc, nil)
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Stop()
+		ts.Close()
+	})
+	return s, ts
+}
+
+// testConn is the client side of a WebSocket connection.
+type testConn struct {
+	t    *testing.T
+	conn net.Conn
+	rw   io.ReadWriter
+}
+
+// dialTestConn connects to path of ts, with header set on the upgrade request.
+func dialTestConn(t *testing.T, ts *httptest.Server, path string, header http.Header) *testConn {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dialer := ws.Dialer{
+		Header: ws.HandshakeHeaderHTTP(header),
+	}
+	conn, br, _, err := dialer.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	var r io.Reader = conn
+	if br != nil {
+		r = io.MultiReader(br, conn)
+	}
+	return &testConn{
+		t:    t,
+		conn: conn,
+		rw: struct {
+			io.Reader
+			io.Writer
+		}{r, conn},
+	}
+}
+
+func (c *testConn) write(op ws.OpCode, b []byte) {
+	if err := wsutil.WriteClientMessage(c.conn, op, b); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testConn) writeFrame(frame *streamFrame) {
+	b, err := json.Marshal(frame)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.write(ws.OpText, b)
+}
+
+// read reads the next message, or returns the error of a closed connection.
+func (c *testConn) read() ([]byte, ws.OpCode, error) {
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return wsutil.ReadServerData(c.rw)
+}
+
+func (c *testConn) readFrame() *streamFrame {
+	b, _, err := c.read()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	frame := &streamFrame{}
+	if err := json.Unmarshal(b, frame); err != nil {
+		c.t.Fatal(err)
+	}
+	return frame
+}
+
+// frameStatus returns the status of a trailer frame.
+func frameStatus(t *testing.T, frame *streamFrame) *status.Status {
+	p := &spb.Status{}
+	if err := protojson.Unmarshal(frame.Status, p); err != nil {
+		t.Fatal(err)
+	}
+	return status.FromProto(p)
+}
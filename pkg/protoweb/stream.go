@@ -2,34 +2,126 @@ package protoweb
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
 	"github.com/gobwas/ws/wsutil"
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
+// closeTimeout bounds how long closing a stream may block on a stuck write.
+const closeTimeout = time.Second
+
 type serverStream struct {
-	ctx  context.Context
-	conn net.Conn
+	// accessed atomically, unix nanoseconds
+	lastActiveAt int64
+	lastPongAt   int64
+
+	ctx         context.Context
+	cancel      cancelCauseFunc
+	desc        *StreamDesc
+	w           http.ResponseWriter
+	r           *http.Request
+	params      httprouter.Params
+	upgrader    *ws.HTTPUpgrader
+	keepalive   StreamKeepaliveParams
+	compression *StreamCompressionParams
+	maxRecvSize int
+	metrics     *streamMetrics
+	limiter     *rateLimiter
+	queue       *sendQueue
+
+	mu         sync.Mutex
+	conn       net.Conn
+	flate      *deflater
+	upgradeErr error
+	header     metadata.MD
+	headerSent bool
+	trailer    metadata.MD
+	closed     bool
+	status     *status.Status
+
+	recv      *recvBuffer
+	counters  messageCounters
+	recvBound bool
+	// framed is set if messages are encoded frames, stamped with sequence
+	// numbers by a resumeSession.
+	framed bool
 }
 
-func newServerStream(ctx context.Context, conn net.Conn) *serverStream {
-	return &serverStream{
-		ctx:  ctx,
-		conn: conn,
+func newServerStream(ctx context.Context, s *Server, sd *StreamDesc, w http.ResponseWriter, r *http.Request, params httprouter.Params) *serverStream {
+	upgrader := *s.upgrader
+	upgrader.Protocol = s.opts.selectProtocol(r)
+	ss := &serverStream{
+		desc:        sd,
+		w:           w,
+		r:           r,
+		params:      params,
+		upgrader:    &upgrader,
+		keepalive:   s.opts.streamKeepalive,
+		compression: s.opts.compression,
+		maxRecvSize: s.opts.maxRecvMsgSize,
+		metrics:     &s.metrics,
+		limiter:     newRateLimiter(s.opts.flowControl),
+		queue:       newSendQueue(s.opts.flowControl, &s.metrics),
+		header:      metadata.MD{},
+		trailer:     metadata.MD{},
+		recv:        newRecvBuffer(s.opts.flowControl.inboundQueue()),
 	}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, &serverStreamTransport{
+		method: sd.StreamName,
+		ss:     ss,
+	})
+	ss.ctx, ss.cancel = withCancelCause(ctx)
+	go func() {
+		select {
+		case <-s.done:
+			ss.abort(status.Convert(ErrServerStopped))
+		case <-ss.ctx.Done():
+		}
+	}()
+	return ss
 }
 
-func (ss *serverStream) SetHeader(metadata.MD) error {
+func (ss *serverStream) SetHeader(md metadata.MD) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ss.header = metadata.Join(ss.header, md)
 	return nil
 }
 
-func (ss *serverStream) SendHeader(metadata.MD) error {
-	return nil
+func (ss *serverStream) SendHeader(md metadata.MD) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.headerSent {
+		return ErrIllegalHeaderWrite
+	}
+	ss.header = metadata.Join(ss.header, md)
+	if err := ss.upgradeLocked(); err != nil {
+		return err
+	}
+	return ss.writeHeaderLocked()
 }
 
-func (ss *serverStream) SetTrailer(metadata.MD) {
+func (ss *serverStream) SetTrailer(md metadata.MD) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.trailer = metadata.Join(ss.trailer, md)
 }
 
 func (ss *serverStream) Context() context.Context {
@@ -37,17 +129,386 @@ func (ss *serverStream) Context() context.Context {
 }
 
 func (ss *serverStream) SendMsg(m interface{}) error {
-	b, err := protojsonMarshalOptions.Marshal(m.(proto.Message))
+	b, err := ss.marshal(m.(proto.Message))
 	if err != nil {
 		return err
 	}
-	return wsutil.WriteServerText(ss.conn, b)
+	return ss.sendPrepared(b)
+}
+
+func (ss *serverStream) encoding() string {
+	return encodingJSON
+}
+
+func (ss *serverStream) marshal(m proto.Message) ([]byte, error) {
+	return protojsonMarshalOptions.Marshal(m)
+}
+
+func (ss *serverStream) sendPrepared(b []byte) error {
+	return ss.counters.sent(ss.sendBytes(b))
+}
+
+func (ss *serverStream) sendBytes(b []byte) error {
+	ss.mu.Lock()
+	if err := ss.upgradeLocked(); err != nil {
+		ss.mu.Unlock()
+		return err
+	}
+	if ss.closed {
+		err := ss.closedErrLocked()
+		ss.mu.Unlock()
+		return err
+	}
+	if err := ss.writeHeaderLocked(); err != nil {
+		ss.mu.Unlock()
+		return err
+	}
+	if ss.queue != nil {
+		ss.mu.Unlock()
+		return ss.queue.push(ss.ctx, b)
+	}
+	defer ss.mu.Unlock()
+	return ss.writeMessageLocked(b)
+}
+
+// writeMessage writes a message taken from the outbound queue.
+func (ss *serverStream) writeMessage(b []byte) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.closed {
+		return ss.closedErrLocked()
+	}
+	return ss.writeMessageLocked(b)
+}
+
+func (ss *serverStream) writeMessageLocked(b []byte) error {
+	op := ws.OpText
+	if ss.framed {
+		op = ws.OpBinary
+	}
+	if err := ss.writeLocked(op, b); err != nil {
+		return err
+	}
+	ss.touch()
+	return nil
 }
 
 func (ss *serverStream) RecvMsg(m interface{}) error {
-	b, err := wsutil.ReadClientText(ss.conn)
+	return ss.counters.received(ss.recvMsg(m))
+}
+
+func (ss *serverStream) recvMsg(m interface{}) error {
+	ss.mu.Lock()
+	if !ss.desc.ClientStreams && ss.desc.Binder != nil {
+		// the request of a server-only stream is bound from the upgrade
+		// request, instead of being sent as the initial frame. The connection
+		// is upgraded once bound, as the handler may not send for a while.
+		bound := ss.recvBound
+		ss.recvBound = true
+		ss.mu.Unlock()
+		if bound {
+			return io.EOF
+		}
+		err := ss.desc.Binder(m, ss.r, ss.params)
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		if err != nil {
+			// rejected with a plain response, as unary requests are
+			ss.rejectLocked(err)
+			return err
+		}
+		return ss.upgradeLocked()
+	}
+	err := ss.upgradeLocked()
+	ss.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	b, err := ss.recv.get(ss.ctx)
+	if err != nil {
+		return err
+	}
+	if err := protojsonUnmarshalOptions.Unmarshal(b, m.(proto.Message)); err != nil {
+		return err
+	}
+	if ss.desc.Binder != nil {
+		return ss.desc.Binder(m, ss.r, ss.params)
+	}
+	return nil
+}
+
+// sendMessageFrame sends payload as a message frame stamped with seq, through
+// the outbound queue if any.
+func (ss *serverStream) sendMessageFrame(seq uint64, payload []byte) error {
+	b, err := json.Marshal(&streamFrame{
+		Type:    frameMessage,
+		Seq:     seq,
+		Payload: payload,
+	})
+	if err != nil {
+		return err
+	}
+	return ss.sendBytes(b)
+}
+
+// sendFrame sends a control frame, upgrading the connection and sending header
+// first if needed.
+func (ss *serverStream) sendFrame(frame *streamFrame) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := ss.upgradeLocked(); err != nil {
+		return err
+	}
+	if ss.closed {
+		return ss.closedErrLocked()
+	}
+	if err := ss.writeHeaderLocked(); err != nil {
+		return err
+	}
+	if err := ss.writeFrameLocked(frame); err != nil {
+		return err
+	}
+	ss.touch()
+	return nil
+}
+
+// finish sends trailer along with the final status of the stream, and closes
+// the connection afterwards.
+func (ss *serverStream) finish(st *status.Status) error {
+	ss.cancel(nil)
+	ss.mu.Lock()
+	err := ss.upgradeLocked()
+	ss.mu.Unlock()
 	if err != nil {
 		return err
 	}
-	return protojsonUnmarshalOptions.Unmarshal(b, m.(proto.Message))
+	if ss.queue != nil {
+		ss.queue.close()
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.closeLocked(st)
+}
+
+// abort closes the stream with given status while the handler may still be
+// running, writes blocked on a dead connection are interrupted.
+func (ss *serverStream) abort(st *status.Status) {
+	ss.cancel(st.Err())
+	ss.recv.close(st.Err())
+	if ss.queue != nil {
+		ss.queue.fail(st.Err())
+	}
+
+	ss.mu.Lock()
+	conn := ss.conn
+	if conn == nil {
+		// not upgraded yet, the request is rejected instead
+		ss.rejectLocked(st.Err())
+		ss.mu.Unlock()
+		return
+	}
+	ss.mu.Unlock()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(closeTimeout))
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	_ = ss.closeLocked(st)
+}
+
+// rejectLocked responds err to the upgrade request, unless the connection is
+// already upgraded or the upgrade has failed. Every later use of the stream
+// fails with err.
+func (ss *serverStream) rejectLocked(err error) {
+	if ss.conn != nil || ss.upgradeErr != nil {
+		return
+	}
+	ss.upgradeErr = err
+	writeStatusResponse(ss.w, httpStatusFromError(err), status.Convert(err))
+}
+
+func (ss *serverStream) transport() string {
+	return TransportWebSocket
+}
+
+func (ss *serverStream) streamCounters() *messageCounters {
+	return &ss.counters
+}
+
+// drop closes the connection without sending the final status.
+func (ss *serverStream) drop() {
+	ss.cancel(nil)
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.conn == nil || ss.closed {
+		return
+	}
+	ss.closed = true
+	_ = ss.conn.Close()
+}
+
+func (ss *serverStream) closeLocked(st *status.Status) error {
+	if ss.closed {
+		return nil
+	}
+	ss.closed = true
+	ss.status = st
+	defer ss.conn.Close()
+	if err := ss.writeHeaderLocked(); err != nil {
+		return err
+	}
+	frame, err := newTrailerFrame(ss.trailer, st)
+	if err != nil {
+		return err
+	}
+	if err := ss.writeFrameLocked(frame); err != nil {
+		return err
+	}
+	return ss.writeLocked(ws.OpClose, ws.NewCloseFrameBody(ws.StatusNormalClosure, ""))
+}
+
+func (ss *serverStream) closedErrLocked() error {
+	if ss.status != nil && ss.status.Code() != codes.OK {
+		return ss.status.Err()
+	}
+	return status.Error(codes.Canceled, "stream is closed")
+}
+
+// upgradeLocked upgrades the connection on first use, header set before that
+// is merged into the upgrade response.
+func (ss *serverStream) upgradeLocked() error {
+	if ss.conn != nil || ss.upgradeErr != nil {
+		return ss.upgradeErr
+	}
+	upgrader := *ss.upgrader
+	if len(ss.header) > 0 {
+		upgrader.Header = http.Header{}
+		for k, v := range ss.upgrader.Header {
+			upgrader.Header[k] = v
+		}
+		writeMetadataToHeader(ss.header, upgrader.Header)
+		ss.headerSent = true
+	}
+	var flateParams *wsflate.Parameters
+	if ss.compression != nil {
+		upgrader.Negotiate = ss.compression.negotiate(&flateParams)
+	}
+	ss.conn, _, _, ss.upgradeErr = upgrader.Upgrade(ss.r, ss.w)
+	if ss.upgradeErr != nil {
+		return ss.upgradeErr
+	}
+	ss.flate = newDeflater(ss.compression, flateParams)
+	ss.touch()
+	if ss.queue != nil {
+		ss.queue.start(ss.writeMessage)
+	}
+	go ss.readLoop()
+	if ss.keepalive.enabled() {
+		go runKeepalive(ss, ss.keepalive)
+	}
+	return nil
+}
+
+// readLoop reads messages into recv and handles control frames, until the
+// connection is closed. Reading stops while recv is full, so that a client
+// sending faster than the handler receives is held back by TCP.
+func (ss *serverStream) readLoop() {
+	err := readMessages(ss.conn, writerFunc(ss.writeControl), ss.flate, ss.maxRecvSize, func() {
+		atomic.StoreInt64(&ss.lastPongAt, time.Now().UnixNano())
+	}, func(b []byte) {
+		ss.touch()
+		if ss.limiter != nil && !ss.limiter.allow() {
+			atomic.AddUint64(&ss.metrics.rateLimited, 1)
+			ss.abort(errInboundRateExceeded)
+			return
+		}
+		_ = ss.recv.put(ss.ctx, b)
+	})
+	if _, ok := err.(wsutil.ClosedError); ok {
+		// close frame has been replied by the control handler
+		ss.mu.Lock()
+		ss.closed = true
+		_ = ss.conn.Close()
+		ss.mu.Unlock()
+	} else if st, ok := status.FromError(err); ok {
+		ss.abort(st)
+		return
+	}
+	cause := connCause(err)
+	ss.recv.close(cause)
+	ss.cancel(cause)
+}
+
+func (ss *serverStream) done() <-chan struct{} {
+	return ss.ctx.Done()
+}
+
+func (ss *serverStream) writePing() error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.closed {
+		return nil
+	}
+	return ss.writeLocked(ws.OpPing, nil)
+}
+
+func (ss *serverStream) touch() {
+	atomic.StoreInt64(&ss.lastActiveAt, time.Now().UnixNano())
+}
+
+// lastActive and lastPong are the current time while recv is full, as pongs
+// and messages are not read until the handler catches up.
+func (ss *serverStream) lastActive() time.Time {
+	if ss.recv.full() {
+		return time.Now()
+	}
+	return time.Unix(0, atomic.LoadInt64(&ss.lastActiveAt))
+}
+
+func (ss *serverStream) lastPong() time.Time {
+	if ss.recv.full() {
+		return time.Now()
+	}
+	return time.Unix(0, atomic.LoadInt64(&ss.lastPongAt))
+}
+
+// writeHeaderLocked sends header as an initial metadata frame, if it was not
+// sent with the upgrade response.
+func (ss *serverStream) writeHeaderLocked() error {
+	if ss.headerSent {
+		return nil
+	}
+	ss.headerSent = true
+	if len(ss.header) == 0 {
+		return nil
+	}
+	return ss.writeFrameLocked(newHeaderFrame(ss.header))
+}
+
+func (ss *serverStream) writeFrameLocked(frame *streamFrame) error {
+	b, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	return ss.writeLocked(ws.OpBinary, b)
+}
+
+func (ss *serverStream) writeLocked(op ws.OpCode, b []byte) error {
+	if t := ss.keepalive.WriteTimeout; t > 0 {
+		_ = ss.conn.SetWriteDeadline(time.Now().Add(t))
+	}
+	return writeMessage(ss.conn, op, b, ss.flate)
+}
+
+// writeControl writes replies to control frames, serialized with other writes.
+func (ss *serverStream) writeControl(p []byte) (int, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.closed {
+		return len(p), nil
+	}
+	if t := ss.keepalive.WriteTimeout; t > 0 {
+		_ = ss.conn.SetWriteDeadline(time.Now().Add(t))
+	}
+	return ss.conn.Write(p)
 }
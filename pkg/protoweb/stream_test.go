@@ -0,0 +1,74 @@
+package protoweb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestServerStreamBindError(t *testing.T) {
+	handled := false
+	s := NewServer()
+	s.register(&ServiceDesc{
+		ServiceName: "test.Test",
+		Streams: []StreamDesc{{
+			StreamName:    "Watch",
+			Path:          "/watch",
+			ServerStreams: true,
+			Binder: func(m interface{}, r *http.Request, params httprouter.Params) error {
+				errs := &ParamErrors{}
+				errs.Add("name", "query", "name", errors.New("required"))
+				return errs.Err()
+			},
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := &wrapperspb.StringValue{}
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				handled = true
+				return stream.SendMsg(m)
+			},
+		}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, newUpgradeRequest("/watch"))
+	if handled {
+		t.Error("handler has continued after binding failed")
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	p := &spb.Status{}
+	if err := protojson.Unmarshal(w.Body.Bytes(), p); err != nil {
+		t.Fatal(err)
+	}
+	st := status.FromProto(p)
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("code = %s, want %s", st.Code(), codes.InvalidArgument)
+	}
+	if details := st.Details(); len(details) != 1 {
+		t.Errorf("details = %v, want a BadRequest", details)
+	} else if br, ok := details[0].(*errdetails.BadRequest); !ok || br.FieldViolations[0].Field != "name" {
+		t.Errorf("details = %v, want a BadRequest of name", details)
+	}
+}
+
+func newUpgradeRequest(target string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	return r
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -64,3 +65,27 @@ func writeMetadataToHeader(md metadata.MD, h http.Header) {
 		}
 	}
 }
+
+// serverStreamTransport exposes a stream as grpc.ServerTransportStream, so
+// that grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer work on streams.
+type serverStreamTransport struct {
+	method string
+	ss     grpc.ServerStream
+}
+
+func (s *serverStreamTransport) Method() string {
+	return s.method
+}
+
+func (s *serverStreamTransport) SetHeader(md metadata.MD) error {
+	return s.ss.SetHeader(md)
+}
+
+func (s *serverStreamTransport) SendHeader(md metadata.MD) error {
+	return s.ss.SendHeader(md)
+}
+
+func (s *serverStreamTransport) SetTrailer(md metadata.MD) error {
+	s.ss.SetTrailer(md)
+	return nil
+}
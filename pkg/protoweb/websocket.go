@@ -0,0 +1,187 @@
+package protoweb
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readMessages reads text messages from src until the connection is closed.
+// Control frames are handled in place, and their replies are written to dst.
+// Compressed messages are decompressed by d, if compression was negotiated.
+// Messages larger than maxSize bytes fail reading with ResourceExhausted.
+func readMessages(src io.Reader, dst io.Writer, d *deflater, maxSize int, onPong func(), onMessage func(b []byte)) error {
+	handleControl := func(hdr ws.Header, r io.Reader) error {
+		if hdr.OpCode == ws.OpPong {
+			onPong()
+		}
+		return wsutil.ControlHandler{
+			Src:                 r,
+			Dst:                 dst,
+			State:               ws.StateServerSide,
+			DisableSrcCiphering: true,
+		}.Handle(hdr)
+	}
+	rd := &wsutil.Reader{
+		Source:         src,
+		State:          ws.StateServerSide,
+		CheckUTF8:      true,
+		OnIntermediate: handleControl,
+	}
+	var msg wsflate.MessageState
+	if d != nil {
+		// compressed messages are checked once decompressed
+		rd.CheckUTF8 = false
+		rd.State |= ws.StateExtended
+		rd.Extensions = []wsutil.RecvExtension{&msg}
+	}
+
+	for {
+		hdr, err := rd.NextFrame()
+		if err != nil {
+			return err
+		}
+		if hdr.OpCode.IsControl() {
+			if err := handleControl(hdr, rd); err != nil {
+				return err
+			}
+			continue
+		}
+		if hdr.OpCode != ws.OpText {
+			if err := rd.Discard(); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(rd, int64(maxSize)+1))
+		if err != nil {
+			return err
+		}
+		if len(b) > maxSize {
+			return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d bytes)", maxSize)
+		}
+		if d != nil {
+			if msg.IsCompressed() {
+				if b, err = d.decompress(b); err != nil {
+					return err
+				}
+			}
+			if !utf8.Valid(b) {
+				return wsutil.ErrInvalidUTF8
+			}
+		}
+		onMessage(b)
+	}
+}
+
+// writerFunc turns a function into io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// recvBuffer queues received messages until they are taken by RecvMsg. At
+// most max messages are queued, put blocks until there is room, so that the
+// reader stops reading from a client faster than the handler.
+type recvBuffer struct {
+	max    int
+	mu     sync.Mutex
+	queue  [][]byte
+	err    error
+	notify chan struct{}
+	space  chan struct{}
+	closed chan struct{}
+}
+
+// newRecvBuffer returns a recvBuffer of max messages, or unbounded if max is 0.
+func newRecvBuffer(max int) *recvBuffer {
+	return &recvBuffer{
+		max:    max,
+		notify: make(chan struct{}, 1),
+		space:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+// put queues m, waiting while the buffer is full until ctx is done. Messages
+// put once the buffer is closed are discarded.
+func (b *recvBuffer) put(ctx context.Context, m []byte) error {
+	for !b.tryPut(m) {
+		select {
+		case <-b.space:
+		case <-b.closed:
+		case <-ctx.Done():
+			return toRPCErr(Cause(ctx))
+		}
+	}
+	return nil
+}
+
+// tryPut queues m unless the buffer is full.
+func (b *recvBuffer) tryPut(m []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return true
+	}
+	if b.max > 0 && len(b.queue) >= b.max {
+		return false
+	}
+	b.queue = append(b.queue, m)
+	signal(b.notify)
+	return true
+}
+
+// full reports whether put would block.
+func (b *recvBuffer) full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.max > 0 && len(b.queue) >= b.max && b.err == nil
+}
+
+// close makes get return err, once all queued messages are taken.
+func (b *recvBuffer) close(err error) {
+	b.mu.Lock()
+	if b.err == nil {
+		b.err = err
+		close(b.closed)
+	}
+	b.mu.Unlock()
+	b.wakeup()
+}
+
+func (b *recvBuffer) get(ctx context.Context) ([]byte, error) {
+	for {
+		b.mu.Lock()
+		if len(b.queue) > 0 {
+			m := b.queue[0]
+			b.queue = b.queue[1:]
+			b.mu.Unlock()
+			signal(b.space)
+			return m, nil
+		}
+		err := b.err
+		b.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case <-b.notify:
+		case <-ctx.Done():
+			return nil, toRPCErr(Cause(ctx))
+		}
+	}
+}
+
+func (b *recvBuffer) wakeup() {
+	signal(b.notify)
+}
@@ -0,0 +1,85 @@
+package protoweb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadMessagesMaxSize(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := wsutil.WriteClientMessage(buf, ws.OpText, []byte("1234")); err != nil {
+		t.Fatal(err)
+	}
+	if err := wsutil.WriteClientMessage(buf, ws.OpText, []byte("12345")); err != nil {
+		t.Fatal(err)
+	}
+
+	var received [][]byte
+	err := readMessages(buf, ioutil.Discard, nil, 4, func() {}, func(b []byte) {
+		received = append(received, b)
+	})
+	if len(received) != 1 || string(received[0]) != "1234" {
+		t.Errorf("received = %q, want [1234]", received)
+	}
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("err = %v, want code %s", err, codes.ResourceExhausted)
+	}
+}
+
+func TestRecvBufferFull(t *testing.T) {
+	b := newRecvBuffer(1)
+	ctx := context.Background()
+	if err := b.put(ctx, []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if !b.full() {
+		t.Fatal("buffer is not full")
+	}
+
+	put := make(chan error, 1)
+	go func() {
+		put <- b.put(ctx, []byte("2"))
+	}()
+	select {
+	case <-put:
+		t.Fatal("put did not wait for room")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if m, err := b.get(ctx); err != nil || string(m) != "1" {
+		t.Fatalf("get = %q, %v", m, err)
+	}
+	if err := <-put; err != nil {
+		t.Fatal(err)
+	}
+	if m, err := b.get(ctx); err != nil || string(m) != "2" {
+		t.Fatalf("get = %q, %v", m, err)
+	}
+
+	b.close(io.EOF)
+	if err := b.put(ctx, []byte("3")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.get(ctx); err != io.EOF {
+		t.Errorf("err = %v, want EOF", err)
+	}
+}
+
+func TestRecvBufferPutCanceled(t *testing.T) {
+	b := newRecvBuffer(1)
+	_ = b.put(context.Background(), []byte("1"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if code := status.Code(b.put(ctx, []byte("2"))); code != codes.Canceled {
+		t.Errorf("code = %s, want %s", code, codes.Canceled)
+	}
+}